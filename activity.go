@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// activityLimit is how many events the activity feed keeps
+const activityLimit = 200
+
+// ActivityKind is what happened in an activity event
+type ActivityKind string
+
+const (
+	ActivityAdded     ActivityKind = "added"
+	ActivityCompleted ActivityKind = "completed"
+	ActivityError     ActivityKind = "error"
+	ActivityNetwork   ActivityKind = "network" // The network came back, so everything resumed
+)
+
+// ActivityEvent is one entry in the activity feed
+type ActivityEvent struct {
+	Time     time.Time    `json:"time"`
+	Kind     ActivityKind `json:"kind"`
+	InfoHash string       `json:"info_hash,omitempty"`
+	Name     string       `json:"name"`
+	Message  string       `json:"message"`
+}
+
+// ActivityPath returns the path of the saved activity feed
+func ActivityPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "activity.json"), nil
+}
+
+// LoadActivity reads the saved activity feed, newest first, returning nothing if it
+// doesn't exist
+func LoadActivity() ([]ActivityEvent, error) {
+	path, err := ActivityPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]ActivityEvent, 0)
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, err
+	}
+	if len(events) > activityLimit {
+		events = events[:activityLimit]
+	}
+	return events, nil
+}
+
+// SaveActivity writes the activity feed, replacing it atomically
+func SaveActivity(events []ActivityEvent) error {
+	path, err := ActivityPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// activityFeed lists recent events across all torrents, newest first
+type activityFeed struct {
+	Content fyne.CanvasObject
+	Events  []ActivityEvent
+
+	// HasTorrent reports whether the torrent of an event is still in the list
+	HasTorrent func(infoHash string) bool
+	// OnOpenFolder and OnSelect are the quick actions for an event's torrent
+	OnOpenFolder func(infoHash string)
+	OnSelect     func(infoHash string)
+	// OnChanged is called after events are added or cleared
+	OnChanged func()
+
+	list *widget.List
+}
+
+// newActivityFeed creates an activity feed showing events
+func newActivityFeed(events []ActivityEvent) *activityFeed {
+	f := &activityFeed{Events: events}
+
+	f.list = widget.NewList(
+		func() int {
+			return len(f.Events)
+		},
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil,
+				container.NewHBox(widget.NewIcon(theme.InfoIcon()), widget.NewLabel("00:00")),
+				container.NewHBox(
+					widget.NewButtonWithIcon("", theme.FolderOpenIcon(), nil),
+					widget.NewButtonWithIcon("", theme.SearchIcon(), nil),
+				),
+				widget.NewLabel("Event"),
+			)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if int(id) >= len(f.Events) {
+				return
+			}
+			event := f.Events[id]
+
+			row := obj.(*fyne.Container)
+			messageLabel := row.Objects[0].(*widget.Label)
+			left := row.Objects[1].(*fyne.Container)
+			right := row.Objects[2].(*fyne.Container)
+			icon := left.Objects[0].(*widget.Icon)
+			timeLabel := left.Objects[1].(*widget.Label)
+			openButton := right.Objects[0].(*widget.Button)
+			selectButton := right.Objects[1].(*widget.Button)
+
+			switch event.Kind {
+			case ActivityAdded:
+				icon.SetResource(theme.ContentAddIcon())
+			case ActivityCompleted:
+				icon.SetResource(theme.ConfirmIcon())
+			case ActivityNetwork:
+				icon.SetResource(theme.InfoIcon())
+			default:
+				icon.SetResource(theme.ErrorIcon())
+			}
+			timeLabel.SetText(formatActivityTime(event.Time))
+			messageLabel.SetText(event.Message)
+
+			// Quick actions only make sense while the torrent is still in the list
+			if event.InfoHash != "" && f.HasTorrent != nil && f.HasTorrent(event.InfoHash) {
+				openButton.Enable()
+				selectButton.Enable()
+			} else {
+				openButton.Disable()
+				selectButton.Disable()
+			}
+			openButton.OnTapped = func() {
+				if f.OnOpenFolder != nil {
+					f.OnOpenFolder(event.InfoHash)
+				}
+			}
+			selectButton.OnTapped = func() {
+				if f.OnSelect != nil {
+					f.OnSelect(event.InfoHash)
+				}
+			}
+		},
+	)
+
+	clearButton := widget.NewButtonWithIcon("Clear", theme.DeleteIcon(), func() {
+		f.Events = nil
+		f.list.Refresh()
+		if f.OnChanged != nil {
+			f.OnChanged()
+		}
+	})
+
+	f.Content = container.NewBorder(
+		container.NewHBox(
+			widget.NewLabelWithStyle("Recent Activity", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			clearButton,
+		),
+		nil, nil, nil,
+		f.list,
+	)
+	return f
+}
+
+// Add records an event at the top of the feed, dropping the oldest beyond the limit
+func (f *activityFeed) Add(event ActivityEvent) {
+	f.Events = append([]ActivityEvent{event}, f.Events...)
+	if len(f.Events) > activityLimit {
+		f.Events = f.Events[:activityLimit]
+	}
+	f.list.Refresh()
+	if f.OnChanged != nil {
+		f.OnChanged()
+	}
+}
+
+// Refresh updates the quick actions after torrents are added or removed
+func (f *activityFeed) Refresh() {
+	f.list.Refresh()
+}
+
+// formatActivityTime shows the time of day for today's events and the date for older ones
+func formatActivityTime(t time.Time) string {
+	now := time.Now()
+	if t.Year() == now.Year() && t.YearDay() == now.YearDay() {
+		return t.Format("15:04")
+	}
+	return t.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errorHalfLife is how long it takes for a torrent's error count to fall by half, so
+// occasional errors spread over a long session never add up to a pause
+const errorHalfLife = 10 * time.Minute
+
+// errorCounter counts a torrent's recent errors, each one fading away over time
+type errorCounter struct {
+	count   float64
+	updated time.Time
+}
+
+// Add records an error at now and returns the count including it
+func (c *errorCounter) Add(now time.Time) float64 {
+	c.count = c.Count(now) + 1
+	c.updated = now
+	return c.count
+}
+
+// Count returns the errors remembered at now, decayed since the last one
+func (c *errorCounter) Count(now time.Time) float64 {
+	if c.count == 0 {
+		return 0
+	}
+	elapsed := now.Sub(c.updated)
+	if elapsed <= 0 {
+		return c.count
+	}
+	return c.count * math.Pow(0.5, float64(elapsed)/float64(errorHalfLife))
+}
+
+// Reset forgets every error, such as when the user resumes the torrent
+func (c *errorCounter) Reset() {
+	c.count = 0
+	c.updated = time.Time{}
+}
+
+// autoPauseReason describes why a torrent was paused for reaching limit errors, ending
+// with the last one
+func autoPauseReason(limit int, err error) string {
+	return fmt.Sprintf("paused after %d errors, the last: %v", limit, err)
+}
+
+// validateErrorPauseLimit accepts a whole number of errors, 0 meaning never pause
+func validateErrorPauseLimit(text string) error {
+	limit, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || limit < 0 {
+		return fmt.Errorf("enter a whole number of errors (0 to never pause)")
+	}
+	return nil
+}
+
+// parseErrorPauseLimit reads a validated error limit
+func parseErrorPauseLimit(text string) int {
+	limit, _ := strconv.Atoi(strings.TrimSpace(text))
+	return limit
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// errorStreak stops a batch of adds once too many fail in a row, which usually means
+// something is wrong with all of them, such as the network being down, and reporting
+// each failure would only bury the cause
+type errorStreak struct {
+	limit   int // Failures in a row that stop the batch, 0 meaning never stop
+	count   int
+	lastErr error
+}
+
+// newErrorStreak creates a streak that stops after limit failures in a row
+func newErrorStreak(limit int) *errorStreak {
+	return &errorStreak{limit: limit}
+}
+
+// Record counts the outcome of one add, reporting whether the batch should stop
+func (s *errorStreak) Record(err error) bool {
+	if err == nil {
+		s.count = 0
+		return false
+	}
+	s.count++
+	s.lastErr = err
+	return s.limit > 0 && s.count >= s.limit
+}
+
+// Err describes why the batch stopped after processed of total items
+func (s *errorStreak) Err(processed, total int) error {
+	return fmt.Errorf("stopped after %d failures in a row, the last being: %v. %d of %d processed",
+		s.count, s.lastErr, processed, total)
+}
+
+// validateErrorStreak accepts a whole number of failures, 0 meaning never stop
+func validateErrorStreak(text string) error {
+	limit, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || limit < 0 {
+		return fmt.Errorf("enter a whole number of failures (0 to never stop)")
+	}
+	return nil
+}
+
+// parseErrorStreak reads a validated failure limit
+func parseErrorStreak(text string) int {
+	limit, _ := strconv.Atoi(strings.TrimSpace(text))
+	return limit
+}
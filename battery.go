@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// errNoBattery is returned where battery status can be read but there is no battery
+var errNoBattery = errors.New("no battery found")
+
+// BatteryStatus is the charge of the computer's battery
+type BatteryStatus struct {
+	Percent  int  // Remaining charge from 0 to 100
+	Charging bool // Plugged in, whether or not the battery is still charging
+}
+
+// pauseForBattery reports whether downloads should be paused on battery power: running on
+// battery at or below threshold percent pauses them, and plugging in or charging above the
+// threshold resumes them
+func pauseForBattery(status BatteryStatus, threshold int) bool {
+	return !status.Charging && status.Percent <= threshold
+}
+
+// validateBatteryThreshold accepts a percentage from 1 to 99
+func validateBatteryThreshold(text string) error {
+	percent, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || percent < 1 || percent > 99 {
+		return fmt.Errorf("enter a percentage from 1 to 99")
+	}
+	return nil
+}
+
+// parseBatteryThreshold reads a validated battery threshold
+func parseBatteryThreshold(text string) int {
+	percent, _ := strconv.Atoi(strings.TrimSpace(text))
+	return percent
+}
@@ -0,0 +1,35 @@
+//go:build darwin
+// +build darwin
+
+package main
+
+import (
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// batterySupported reports whether readBattery works on this platform
+const batterySupported = true
+
+// pmsetCharge matches the charge of a battery in the output of pmset
+var pmsetCharge = regexp.MustCompile(`(\d+)%;`)
+
+// readBattery reads the battery from pmset, whose first line names the power source, as in
+// "Now drawing from 'Battery Power'"
+func readBattery() (BatteryStatus, error) {
+	out, err := exec.Command("pmset", "-g", "batt").Output()
+	if err != nil {
+		return BatteryStatus{}, err
+	}
+	m := pmsetCharge.FindSubmatch(out)
+	if m == nil {
+		return BatteryStatus{}, errNoBattery
+	}
+	percent, _ := strconv.Atoi(string(m[1]))
+	return BatteryStatus{
+		Percent:  percent,
+		Charging: strings.Contains(string(out), "'AC Power'"),
+	}, nil
+}
@@ -0,0 +1,50 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// batterySupported reports whether readBattery works on this platform
+const batterySupported = true
+
+// readBattery reads the battery from /sys/class/power_supply, averaging the charge of
+// laptops with more than one battery
+func readBattery() (BatteryStatus, error) {
+	read := func(path string) string {
+		data, _ := os.ReadFile(path)
+		return strings.TrimSpace(string(data))
+	}
+
+	supplies, _ := filepath.Glob("/sys/class/power_supply/*")
+	var status BatteryStatus
+	batteries, total := 0, 0
+	for _, supply := range supplies {
+		switch read(filepath.Join(supply, "type")) {
+		case "Mains", "USB":
+			if read(filepath.Join(supply, "online")) == "1" {
+				status.Charging = true
+			}
+		case "Battery":
+			percent, err := strconv.Atoi(read(filepath.Join(supply, "capacity")))
+			if err != nil {
+				continue
+			}
+			batteries++
+			total += percent
+			if read(filepath.Join(supply, "status")) != "Discharging" {
+				status.Charging = true
+			}
+		}
+	}
+	if batteries == 0 {
+		return BatteryStatus{}, errNoBattery
+	}
+	status.Percent = total / batteries
+	return status, nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package main
+
+import "errors"
+
+// batterySupported reports whether readBattery works on this platform
+const batterySupported = false
+
+// readBattery can't read the battery on this platform
+func readBattery() (BatteryStatus, error) {
+	return BatteryStatus{}, errors.New("battery status is unknown on this platform")
+}
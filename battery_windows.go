@@ -0,0 +1,41 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// batterySupported reports whether readBattery works on this platform
+const batterySupported = true
+
+// systemPowerStatus is SYSTEM_POWER_STATUS from the Windows API
+type systemPowerStatus struct {
+	ACLineStatus        byte
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+var procGetSystemPowerStatus = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetSystemPowerStatus")
+
+// readBattery reads the battery with GetSystemPowerStatus
+func readBattery() (BatteryStatus, error) {
+	var s systemPowerStatus
+	if r, _, err := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&s))); r == 0 {
+		return BatteryStatus{}, err
+	}
+	// 128 means there is no battery, and 255 that the charge is unknown
+	if s.BatteryFlag == 128 || s.BatteryLifePercent == 255 {
+		return BatteryStatus{}, errNoBattery
+	}
+	return BatteryStatus{
+		Percent:  int(s.BatteryLifePercent),
+		Charging: s.ACLineStatus == 1,
+	}, nil
+}
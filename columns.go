@@ -0,0 +1,188 @@
+package main
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// minColumnWidth keeps a dragged column wide enough to read and to grab again
+const minColumnWidth = 40
+
+// Default widths of the Files list's Name, Size and Priority columns. The name takes
+// whatever space is left, its width being only its minimum.
+var defaultFileColumnWidths = []float32{120, 90, 110}
+
+// Default widths of the Peers list's Address, Client, Progress and Speed columns
+var defaultPeerColumnWidths = []float32{200, 160, 80, 100}
+
+// columnWidthsOr returns a copy of saved if it has a usable width for every column,
+// otherwise a copy of defaults, so a settings file from another version can't break a list
+func columnWidthsOr(saved, defaults []float32) []float32 {
+	if len(saved) != len(defaults) {
+		return append([]float32(nil), defaults...)
+	}
+	for _, width := range saved {
+		if width < minColumnWidth {
+			return append([]float32(nil), defaults...)
+		}
+	}
+	return append([]float32(nil), saved...)
+}
+
+// listColumns lays out the columns of a list's rows and of its header at the same widths,
+// which are changed by dragging the header's dividers
+type listColumns struct {
+	// Widths holds the width of each column
+	Widths []float32
+
+	// OnResized is called after a divider has been dragged
+	OnResized func()
+
+	stretch int // Column that takes the space left over, or -1 for none
+	rows    []*fyne.Container
+}
+
+// newListColumns creates columns of widths, column stretch taking any spare space
+func newListColumns(widths []float32, stretch int) *listColumns {
+	return &listColumns{Widths: widths, stretch: stretch}
+}
+
+// Row creates a container laying out cells, one per column
+func (c *listColumns) Row(cells ...fyne.CanvasObject) *fyne.Container {
+	row := container.New(&columnLayout{columns: c}, cells...)
+	c.rows = append(c.rows, row)
+	return row
+}
+
+// Header creates a row of column titles, each with a divider to drag for its width
+func (c *listColumns) Header(titles ...string) *fyne.Container {
+	cells := make([]fyne.CanvasObject, 0, len(titles))
+	for i, title := range titles {
+		label := widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+		label.Truncation = fyne.TextTruncateEllipsis
+		if i == c.stretch {
+			cells = append(cells, label)
+			continue
+		}
+		cells = append(cells, container.NewBorder(nil, nil, nil, newColumnDivider(c, i), label))
+	}
+	return c.Row(cells...)
+}
+
+// SetWidths changes every column's width at once
+func (c *listColumns) SetWidths(widths []float32) {
+	c.Widths = widths
+	c.refresh()
+}
+
+// resize widens column index by dx, or narrows it for a negative dx
+func (c *listColumns) resize(index int, dx float32) {
+	c.Widths[index] = max(c.Widths[index]+dx, minColumnWidth)
+	c.refresh()
+}
+
+// refresh lays out every row again at the current widths
+func (c *listColumns) refresh() {
+	for _, row := range c.rows {
+		row.Refresh()
+	}
+}
+
+// cellWidths returns the width of each of count cells in a row of total width
+func (c *listColumns) cellWidths(count int, total float32) []float32 {
+	widths := make([]float32, count)
+	used := theme.Padding() * float32(max(count-1, 0))
+	for i := range widths {
+		if i < len(c.Widths) {
+			widths[i] = c.Widths[i]
+		} else {
+			widths[i] = minColumnWidth
+		}
+		used += widths[i]
+	}
+	if c.stretch >= 0 && c.stretch < count && total > used {
+		widths[c.stretch] += total - used
+	}
+	return widths
+}
+
+// columnSpacer creates an empty space as large as like, so a header lines up with rows
+// that hold more than its columns
+func columnSpacer(like fyne.CanvasObject) fyne.CanvasObject {
+	spacer := canvas.NewRectangle(color.Transparent)
+	spacer.SetMinSize(like.MinSize())
+	return spacer
+}
+
+// columnLayout places a row's cells side by side at their columns' widths
+type columnLayout struct {
+	columns *listColumns
+}
+
+// Layout implements fyne.Layout
+func (l *columnLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	x := float32(0)
+	for i, width := range l.columns.cellWidths(len(objects), size.Width) {
+		objects[i].Move(fyne.NewPos(x, 0))
+		objects[i].Resize(fyne.NewSize(width, size.Height))
+		x += width + theme.Padding()
+	}
+}
+
+// MinSize implements fyne.Layout
+func (l *columnLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	size := fyne.NewSize(0, 0)
+	for i, width := range l.columns.cellWidths(len(objects), 0) {
+		if i > 0 {
+			size.Width += theme.Padding()
+		}
+		size.Width += width
+		size.Height = max(size.Height, objects[i].MinSize().Height)
+	}
+	return size
+}
+
+// columnDivider is the line at the right of a column's title, dragged to resize the column
+type columnDivider struct {
+	widget.BaseWidget
+
+	columns *listColumns
+	index   int
+}
+
+// newColumnDivider creates the divider of column index
+func newColumnDivider(columns *listColumns, index int) *columnDivider {
+	d := &columnDivider{columns: columns, index: index}
+	d.ExtendBaseWidget(d)
+	return d
+}
+
+// CreateRenderer implements fyne.Widget
+func (d *columnDivider) CreateRenderer() fyne.WidgetRenderer {
+	line := canvas.NewRectangle(theme.Color(theme.ColorNameSeparator))
+	line.SetMinSize(fyne.NewSize(theme.Padding(), theme.Padding()))
+	return widget.NewSimpleRenderer(line)
+}
+
+// Cursor implements desktop.Cursorable
+func (d *columnDivider) Cursor() desktop.Cursor {
+	return desktop.HResizeCursor
+}
+
+// Dragged implements fyne.Draggable
+func (d *columnDivider) Dragged(e *fyne.DragEvent) {
+	d.columns.resize(d.index, e.Dragged.DX)
+}
+
+// DragEnd implements fyne.Draggable
+func (d *columnDivider) DragEnd() {
+	if d.columns.OnResized != nil {
+		d.columns.OnResized()
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Config holds the user settings that persist between launches
+type Config struct {
+	Categories         []string `json:"categories"`           // Categories offered in the Add dialog
+	SavePathTemplate   string   `json:"save_path_template"`   // Layout of each torrent's save path under the data dir
+	CheckExistingFiles bool     `json:"check_existing_files"` // Default for verifying data already on disk when adding
+	Preallocate        bool     `json:"preallocate"`          // Default for reserving files' full size instead of sparse files
+	ShowSwarmTotals    bool     `json:"show_swarm_totals"`    // Show the peer and seed totals in the status bar
+	ShowSpeedGraph     bool     `json:"show_speed_graph"`     // Show the download speed graph in the status bar
+	ConfirmRemove      bool     `json:"confirm_remove"`       // Ask before removing; quick removal never deletes data
+	KeepActivity       bool     `json:"keep_activity"`        // Save the activity feed between launches
+	CommandPalette     bool     `json:"command_palette"`      // Open the command palette with Ctrl+K
+	WatchClipboard     bool     `json:"watch_clipboard"`      // Offer to add torrent links copied elsewhere
+	VerifyMagnets      bool     `json:"verify_magnets"`       // Check fetched metadata against the magnet link's info-hash
+	WebhookEnabled     bool     `json:"webhook_enabled"`      // POST to WebhookURL when a torrent completes
+	WebhookURL         string   `json:"webhook_url"`          // Where completion events are posted as JSON
+
+	StartupPolicy   StartupPolicy `json:"startup_policy"`    // Whether restored torrents start paused
+	BatchErrorLimit int           `json:"batch_error_limit"` // Failed adds in a row that stop a batch, 0 meaning never stop
+	ErrorPauseLimit int           `json:"error_pause_limit"` // Recent errors that pause a torrent, 0 meaning never pause
+
+	// Speed limits in KiB/s, 0 meaning unlimited
+	DownloadLimit    int64         `json:"download_limit"`
+	UploadLimit      int64         `json:"upload_limit"`
+	AltDownloadLimit int64         `json:"alt_download_limit"`
+	AltUploadLimit   int64         `json:"alt_upload_limit"`
+	AltSpeedEnabled  bool          `json:"alt_speed_enabled"` // Always use the alternative limits
+	ScheduleEnabled  bool          `json:"schedule_enabled"`  // Use the alternative limits during scheduled hours
+	Schedule         SpeedSchedule `json:"schedule"`
+
+	UploadSlots    int `json:"upload_slots"`    // Peers each torrent uploads to at once, 0 meaning unlimited
+	MaxConnections int `json:"max_connections"` // Connections across all torrents, 0 meaning unlimited
+
+	QueueOrder         QueueOrder   `json:"queue_order"`          // How the queue is ordered
+	LibraryFilter      StatusFilter `json:"library_filter"`       // Which torrents the library shows
+	MaxActiveDownloads int          `json:"max_active_downloads"` // Torrents downloading at once, 0 meaning unlimited
+
+	SeedingGoalHours int  `json:"seeding_goal_hours"` // Hours a completed torrent seeds before it is done
+	AutoRemove       bool `json:"auto_remove"`        // Remove done torrents from the list, keeping their data
+	AutoRemoveGrace  int  `json:"auto_remove_grace"`  // Minutes to wait after the seeding goal before removing
+	AutoRemoveNotify bool `json:"auto_remove_notify"` // Send a notification for each automatic removal
+
+	MinRatio float64 `json:"min_ratio"` // All-time share ratio to keep up, 0 meaning no warning
+
+	// Fetching .torrent files from the web: seconds each attempt may take, and the largest
+	// file accepted in MiB
+	TorrentURLTimeout int `json:"torrent_url_timeout"`
+	TorrentURLMaxSize int `json:"torrent_url_max_size"`
+
+	IPStack IPStack       `json:"ip_stack"` // IP versions used for peers and trackers, applied on launch
+	Proxy   ProxySettings `json:"proxy"`    // Proxy for tracker, web seed, peer and .torrent link traffic, applied on launch
+
+	LogLevel LogLevel `json:"log_level"` // Least severe messages logged by Reed and the client, applied on launch
+
+	// Column widths of the Files and Peers lists, empty for the defaults
+	FileColumnWidths []float32 `json:"file_column_widths,omitempty"`
+	PeerColumnWidths []float32 `json:"peer_column_widths,omitempty"`
+
+	BindInterface string `json:"bind_interface"` // Interface name or IP to connect from, empty for any, applied on launch
+	KillSwitch    bool   `json:"kill_switch"`    // Pause everything while the bound interface is down
+
+	PauseOnBattery   bool `json:"pause_on_battery"`  // Pause everything on battery power below BatteryThreshold
+	BatteryThreshold int  `json:"battery_threshold"` // Battery percentage to pause at
+}
+
+// DefaultConfig returns the settings used on first launch
+func DefaultConfig() *Config {
+	return &Config{
+		Categories:       []string{},
+		SavePathTemplate: DefaultSavePathTemplate,
+		ShowSwarmTotals:  true,
+		ShowSpeedGraph:   true,
+		ConfirmRemove:    true,
+		CommandPalette:   true,
+		WatchClipboard:   true,
+		VerifyMagnets:    true,
+		IPStack:          IPStackDual,
+		LogLevel:         LogLevelWarn,
+		QueueOrder:       QueueOrderPriority,
+		StartupPolicy:    StartupRestore,
+		BatchErrorLimit:  5,
+		ErrorPauseLimit:  10,
+		AutoRemoveGrace:  60,
+		MaxConnections:   200,
+		BatteryThreshold: 20,
+
+		TorrentURLTimeout: 30,
+		TorrentURLMaxSize: 10,
+	}
+}
+
+// ConfigDir returns the directory where Reed keeps its settings
+func ConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "reed"), nil
+}
+
+// ConfigPath returns the path of the settings file
+func ConfigPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// LoadConfig reads the settings file, returning the defaults if it doesn't exist yet
+func LoadConfig() (*Config, error) {
+	cfg := DefaultConfig()
+
+	path, err := ConfigPath()
+	if err != nil {
+		return cfg, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	// Fields missing from older files keep their default values
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return DefaultConfig(), err
+	}
+	return cfg, nil
+}
+
+// Save writes the settings file, replacing it atomically
+func (c *Config) Save() error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
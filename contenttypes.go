@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// contentTypes maps file extensions to the kind of content they hold
+var contentTypes = map[string]string{
+	".mkv": "Video", ".mp4": "Video", ".avi": "Video", ".mov": "Video", ".wmv": "Video",
+	".webm": "Video", ".m4v": "Video", ".mpg": "Video", ".mpeg": "Video", ".ts": "Video",
+	".mp3": "Audio", ".flac": "Audio", ".wav": "Audio", ".aac": "Audio", ".ogg": "Audio",
+	".opus": "Audio", ".m4a": "Audio", ".wma": "Audio", ".ape": "Audio",
+	".jpg": "Images", ".jpeg": "Images", ".png": "Images", ".gif": "Images", ".bmp": "Images",
+	".webp": "Images", ".tiff": "Images",
+	".zip": "Archives", ".rar": "Archives", ".7z": "Archives", ".tar": "Archives", ".gz": "Archives",
+	".bz2": "Archives", ".xz": "Archives", ".zst": "Archives",
+	".iso": "Disk Images", ".img": "Disk Images", ".dmg": "Disk Images",
+	".pdf": "Documents", ".epub": "Documents", ".mobi": "Documents", ".txt": "Documents",
+	".doc": "Documents", ".docx": "Documents", ".nfo": "Documents", ".md": "Documents",
+	".srt": "Subtitles", ".ass": "Subtitles", ".ssa": "Subtitles", ".sub": "Subtitles", ".vtt": "Subtitles",
+	".exe": "Programs", ".msi": "Programs", ".apk": "Programs", ".deb": "Programs", ".rpm": "Programs",
+	".appimage": "Programs",
+}
+
+// ContentType is the files of one kind in a torrent
+type ContentType struct {
+	Name  string
+	Files int
+	Size  int64
+}
+
+// contentTypeOf returns the kind of content in a file from its extension, such as Video.
+// Multi-part archives like .r00 count as archives.
+func contentTypeOf(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if kind, ok := contentTypes[ext]; ok {
+		return kind
+	}
+	if len(ext) == 4 && ext[1] == 'r' && ext[2] >= '0' && ext[2] <= '9' && ext[3] >= '0' && ext[3] <= '9' {
+		return "Archives"
+	}
+	return "Other"
+}
+
+// contentBreakdown totals a torrent's files by kind, largest first. Padding files that
+// only align pieces are left out.
+func contentBreakdown(info *metainfo.Info) []ContentType {
+	totals := make(map[string]*ContentType)
+	for _, f := range info.UpvertedFiles() {
+		if strings.Contains(f.Attr, "p") {
+			continue
+		}
+		kind := contentTypeOf(f.DisplayPath(info))
+		if totals[kind] == nil {
+			totals[kind] = &ContentType{Name: kind}
+		}
+		totals[kind].Files++
+		totals[kind].Size += f.Length
+	}
+
+	breakdown := make([]ContentType, 0, len(totals))
+	for _, total := range totals {
+		breakdown = append(breakdown, *total)
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].Size != breakdown[j].Size {
+			return breakdown[i].Size > breakdown[j].Size
+		}
+		return breakdown[i].Name < breakdown[j].Name
+	})
+	return breakdown
+}
+
+// formatContentBreakdown summarizes a breakdown on one line, such as
+// "Video: 2 files, 4.3 GB · Subtitles: 2 files, 80 KB"
+func formatContentBreakdown(breakdown []ContentType) string {
+	parts := make([]string, 0, len(breakdown))
+	for _, kind := range breakdown {
+		parts = append(parts, fmt.Sprintf("%s: %d file(s), %s", kind.Name, kind.Files, HumanReadableSize(kind.Size)))
+	}
+	return strings.Join(parts, " · ")
+}
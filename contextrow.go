@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// contextRow wraps a list row so it can show a context menu on right click. Ordinary taps
+// still reach the list, so selection keeps working.
+type contextRow struct {
+	widget.BaseWidget
+
+	Content fyne.CanvasObject
+
+	// OnTappedSecondary is called with the position of a right click
+	OnTappedSecondary func(*fyne.PointEvent)
+}
+
+// newContextRow creates a row showing content
+func newContextRow(content fyne.CanvasObject) *contextRow {
+	r := &contextRow{Content: content}
+	r.ExtendBaseWidget(r)
+	return r
+}
+
+// CreateRenderer implements fyne.Widget
+func (r *contextRow) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(r.Content)
+}
+
+// TappedSecondary implements fyne.SecondaryTappable
+func (r *contextRow) TappedSecondary(e *fyne.PointEvent) {
+	if r.OnTappedSecondary != nil {
+		r.OnTappedSecondary(e)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/anacrolix/torrent/bencode"
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// Piece lengths offered when creating a torrent. Smaller pieces make the metainfo large
+// and add overhead per piece; larger ones waste more data on each failed hash check.
+const (
+	minPieceLength = 16 << 10
+	maxPieceLength = 16 << 20
+)
+
+// pieceLengthWarnings are the piece counts beyond which a piece length is a poor fit
+const (
+	fewPiecesWarning  = 100
+	manyPiecesWarning = 10000
+)
+
+// pieceLengthChoices lists every allowed piece length, smallest first
+func pieceLengthChoices() []int64 {
+	choices := make([]int64, 0)
+	for length := int64(minPieceLength); length <= maxPieceLength; length <<= 1 {
+		choices = append(choices, length)
+	}
+	return choices
+}
+
+// formatPieceLength names a piece length in whole KiB or MiB, such as "256 KiB"
+func formatPieceLength(pieceLength int64) string {
+	if pieceLength >= 1<<20 {
+		return fmt.Sprintf("%d MiB", pieceLength>>20)
+	}
+	return fmt.Sprintf("%d KiB", pieceLength>>10)
+}
+
+// suggestPieceLength picks a piece length giving around 1000 to 2000 pieces, the usual
+// rule of thumb, within the allowed range
+func suggestPieceLength(totalSize int64) int64 {
+	return min(max(metainfo.ChoosePieceLength(totalSize), minPieceLength), maxPieceLength)
+}
+
+// pieceCount returns how many pieces content of totalSize splits into
+func pieceCount(totalSize, pieceLength int64) int64 {
+	if pieceLength <= 0 {
+		return 0
+	}
+	return (totalSize + pieceLength - 1) / pieceLength
+}
+
+// validatePieceLength accepts a power of two within the allowed range
+func validatePieceLength(pieceLength int64) error {
+	if pieceLength < minPieceLength || pieceLength > maxPieceLength || pieceLength&(pieceLength-1) != 0 {
+		return fmt.Errorf("the piece length must be a power of two from %s to %s",
+			formatPieceLength(minPieceLength), formatPieceLength(maxPieceLength))
+	}
+	return nil
+}
+
+// describePieces shows the piece count for a piece length, warning when it is far from
+// the usual range
+func describePieces(totalSize, pieceLength int64) string {
+	count := pieceCount(totalSize, pieceLength)
+	text := fmt.Sprintf("%d pieces of %s", count, formatPieceLength(pieceLength))
+	switch {
+	case count > manyPiecesWarning:
+		text += " (many pieces make a large .torrent file; a larger piece length is better)"
+	case count < fewPiecesWarning && pieceLength > suggestPieceLength(totalSize):
+		text += " (few pieces make each failed check costly; a smaller piece length is better)"
+	}
+	return text
+}
+
+// contentSize adds up the size of a file, or of every file in a folder
+func contentSize(path string) (int64, error) {
+	var size int64
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// createTorrent hashes the file or folder at path into a new torrent announcing to
+// trackers, one tracker per tier
+func createTorrent(path string, pieceLength int64, trackers []string, comment string, private bool) (*metainfo.MetaInfo, error) {
+	if err := validatePieceLength(pieceLength); err != nil {
+		return nil, err
+	}
+	info := metainfo.Info{PieceLength: pieceLength}
+	if private {
+		isPrivate := true
+		info.Private = &isPrivate
+	}
+	if err := info.BuildFromFilePath(path); err != nil {
+		return nil, err
+	}
+	infoBytes, err := bencode.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+
+	mi := &metainfo.MetaInfo{
+		InfoBytes:    infoBytes,
+		Comment:      comment,
+		CreatedBy:    "Reed",
+		CreationDate: time.Now().Unix(),
+	}
+	if len(trackers) > 0 {
+		mi.Announce = trackers[0]
+		if len(trackers) > 1 {
+			mi.AnnounceList = trackerTiers(trackers)
+		}
+	}
+	return mi, nil
+}
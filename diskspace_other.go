@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package main
+
+import "errors"
+
+// freeDiskSpace can't read the free space on this platform
+func freeDiskSpace(dir string) (int64, error) {
+	return 0, errors.New("free disk space is unknown on this platform")
+}
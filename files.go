@@ -0,0 +1,302 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/anacrolix/torrent"
+)
+
+// FilePriority is how eagerly a selected file is downloaded
+type FilePriority string
+
+const (
+	FilePriorityNormal FilePriority = "normal"
+	FilePriorityHigh   FilePriority = "high"
+)
+
+// FilePriorityNames lists the priorities in the order they are offered in the UI
+var FilePriorityNames = []string{"Normal", "High"}
+
+// String returns the name shown in the UI
+func (p FilePriority) String() string {
+	if p == FilePriorityHigh {
+		return "High"
+	}
+	return "Normal"
+}
+
+// ParseFilePriority converts a UI name back into a FilePriority
+func ParseFilePriority(name string) FilePriority {
+	if name == "High" {
+		return FilePriorityHigh
+	}
+	return FilePriorityNormal
+}
+
+// piecePriority maps a file's selection and priority onto anacrolix piece priorities
+func (f FileInfo) piecePriority() torrent.PiecePriority {
+	if !f.Selected {
+		return torrent.PiecePriorityNone
+	}
+	if f.Priority == FilePriorityHigh {
+		return torrent.PiecePriorityHigh
+	}
+	return torrent.PiecePriorityNormal
+}
+
+// DisplayName returns the file's alias, or the last component of its path
+func (f FileInfo) DisplayName() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return filepath.Base(f.Path)
+}
+
+// newFileInfos describes the files of a torrent with everything selected at normal priority
+func newFileInfos(t *torrent.Torrent) []FileInfo {
+	files := make([]FileInfo, 0, len(t.Files()))
+	for _, f := range t.Files() {
+		files = append(files, FileInfo{
+			Path:     f.DisplayPath(),
+			Size:     f.Length(),
+			Selected: true,
+			Priority: FilePriorityNormal,
+		})
+	}
+	return files
+}
+
+// mergeFileInfos carries saved selections, priorities and aliases over to the files of a torrent,
+// matching them by path
+func mergeFileInfos(files, saved []FileInfo) []FileInfo {
+	savedByPath := make(map[string]FileInfo, len(saved))
+	for _, f := range saved {
+		savedByPath[f.Path] = f
+	}
+	for i := range files {
+		if f, ok := savedByPath[files[i].Path]; ok {
+			files[i].Selected = f.Selected
+			files[i].Priority = f.Priority
+			files[i].Alias = f.Alias
+		}
+	}
+	return files
+}
+
+// applyFileSelections tells anacrolix which files to download and how eagerly. This replaces
+// Torrent.DownloadAll, which would raise every piece regardless of file selection.
+func applyFileSelections(t *torrent.Torrent, files []FileInfo) {
+	handles := t.Files()
+	for i, f := range files {
+		if i >= len(handles) {
+			break
+		}
+		handles[i].SetPriority(f.piecePriority())
+	}
+}
+
+// recheckFile hashes again the pieces holding file index of t, which is much quicker than
+// rechecking the whole torrent. Pieces shared with neighbouring files are checked too.
+// It returns how many of the pieces verified.
+func recheckFile(t *torrent.Torrent, index int) (good, total int) {
+	f := t.Files()[index]
+	begin, end := f.BeginPieceIndex(), f.EndPieceIndex()
+	total = end - begin
+	for i := begin; i < end; i++ {
+		piece := t.Piece(i)
+		piece.VerifyData()
+		if piece.State().Complete {
+			good++
+		}
+	}
+	return good, total
+}
+
+// selectedSize returns the total size of the selected files, which is what will actually be
+// written to disk
+func selectedSize(files []FileInfo) (size int64, count int) {
+	for _, f := range files {
+		if f.Selected {
+			size += f.Size
+			count++
+		}
+	}
+	return size, count
+}
+
+// selectedRemaining returns how much of the selected files of t is still to be downloaded
+func selectedRemaining(t *torrent.Torrent, files []FileInfo) int64 {
+	var remaining int64
+	for i, f := range t.Files() {
+		if i < len(files) && files[i].Selected {
+			remaining += f.Length() - f.BytesCompleted()
+		}
+	}
+	return remaining
+}
+
+// checkDiskSpace makes sure there is room for needed more bytes under dir, which may not
+// exist yet. Platforms that can't report free space pass the check.
+func checkDiskSpace(dir string, needed int64) error {
+	// Measure the nearest folder that already exists
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	free, err := freeDiskSpace(dir)
+	if err != nil {
+		return nil
+	}
+	if needed > free {
+		return fmt.Errorf("not enough disk space in %s: %s needed for the selected files, %s free",
+			dir, HumanReadableSize(needed), HumanReadableSize(free))
+	}
+	return nil
+}
+
+// dataFilePath returns where file index of a torrent is stored on disk
+func dataFilePath(item *TorrentItem, index int) string {
+	info := item.Handle.Info()
+	handles := item.Handle.Files()
+
+	// Single-file torrents are stored directly at the save path
+	if info == nil || len(info.Files) == 0 || index >= len(handles) {
+		return item.SavePath
+	}
+
+	fileInfo := handles[index].FileInfo()
+	return filepath.Join(item.SavePath, filepath.Join(fileInfo.BestPath()...))
+}
+
+// revealDataFile shows a file in the file manager, or opens its folder if the file
+// hasn't been created yet
+func revealDataFile(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return revealPath(path)
+	}
+	if _, err := os.Stat(filepath.Dir(path)); err == nil {
+		return openPath(filepath.Dir(path))
+	}
+	return fmt.Errorf("%s has not been created yet", path)
+}
+
+// openDataFolder opens the folder holding a torrent's data
+func openDataFolder(item *TorrentItem) error {
+	if item.SavePath == "" {
+		return errors.New("save path is unknown")
+	}
+
+	// Multi-file torrents have their own folder; single files are shown in their parent
+	info, err := os.Stat(item.SavePath)
+	if err != nil {
+		return revealDataFile(item.SavePath)
+	}
+	if info.IsDir() {
+		return openPath(item.SavePath)
+	}
+	return revealPath(item.SavePath)
+}
+
+// dataFilePaths lists where every file of a torrent is stored on disk
+func dataFilePaths(item *TorrentItem) []string {
+	count := len(item.Handle.Files())
+	if count == 0 {
+		return []string{item.SavePath}
+	}
+	paths := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		paths = append(paths, dataFilePath(item, i))
+	}
+	return paths
+}
+
+// exclusiveDataPaths drops the paths among item's data that another torrent also stores at,
+// such as a cross-seed of the same files, so deleting item's data never takes theirs.
+// reserved holds the overlapping save paths of other torrents; the files of those not in
+// torrents yet aren't known, so everything overlapping their path is kept.
+func exclusiveDataPaths(paths []string, item *TorrentItem, infoHash string, torrents map[string]*TorrentItem, reserved map[string]string) []string {
+	shared := make(map[string]bool)
+	for _, other := range savePathOwners(torrents, item.SavePath, infoHash) {
+		if other.Handle == nil {
+			continue
+		}
+		for _, path := range dataFilePaths(other) {
+			shared[strings.ToLower(filepath.Clean(path))] = true
+		}
+	}
+
+	pending := make([]string, 0)
+	for hash, path := range reserved {
+		if _, listed := torrents[hash]; !listed {
+			pending = append(pending, path)
+		}
+	}
+
+	exclusive := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if shared[strings.ToLower(filepath.Clean(path))] || slices.ContainsFunc(pending, func(p string) bool {
+			return savePathsOverlap(p, path)
+		}) {
+			log.Printf("Warning: keeping %s, which another torrent also uses", path)
+			continue
+		}
+		exclusive = append(exclusive, path)
+	}
+	return exclusive
+}
+
+// deleteDataFiles removes a torrent's files one at a time so the deletion can be canceled
+// by closing cancel. progress is called after each file. Folders under root left empty
+// are removed once every file is gone. It returns the files that were deleted.
+func deleteDataFiles(paths []string, root string, cancel <-chan struct{}, progress func(done, total int)) ([]string, error) {
+	deleted := make([]string, 0, len(paths))
+	errs := make([]error, 0)
+
+	for i, path := range paths {
+		select {
+		case <-cancel:
+			return deleted, errors.Join(errs...)
+		default:
+		}
+
+		err := os.Remove(path)
+		if err == nil {
+			deleted = append(deleted, path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+		if progress != nil {
+			progress(i+1, len(paths))
+		}
+	}
+
+	// Clear out the folders of multi-file torrents, deepest first
+	if info, err := os.Stat(root); err == nil && info.IsDir() {
+		dirs := make([]string, 0)
+		filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err == nil && d.IsDir() {
+				dirs = append(dirs, path)
+			}
+			return nil
+		})
+		for i := len(dirs) - 1; i >= 0; i-- {
+			// Remove fails on folders that still hold other files, which are left alone
+			os.Remove(dirs[i])
+		}
+	}
+
+	return deleted, errors.Join(errs...)
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// StatusFilter picks which torrents the library shows by their state
+type StatusFilter string
+
+const (
+	FilterAll         StatusFilter = ""
+	FilterActive      StatusFilter = "active"
+	FilterDownloading StatusFilter = "downloading"
+	FilterSeeding     StatusFilter = "seeding"
+	FilterPaused      StatusFilter = "paused"
+	FilterCompleted   StatusFilter = "completed"
+	FilterError       StatusFilter = "error"
+)
+
+// StatusFilters lists the filters in the order of the filter bar
+var StatusFilters = []StatusFilter{
+	FilterAll, FilterActive, FilterDownloading, FilterSeeding, FilterPaused, FilterCompleted, FilterError,
+}
+
+// String returns the name shown on the filter's button
+func (f StatusFilter) String() string {
+	switch f {
+	case FilterActive:
+		return "Active"
+	case FilterDownloading:
+		return "Downloading"
+	case FilterSeeding:
+		return "Seeding"
+	case FilterPaused:
+		return "Paused"
+	case FilterCompleted:
+		return "Completed"
+	case FilterError:
+		return "Error"
+	default:
+		return "All"
+	}
+}
+
+// Matches reports whether a torrent belongs under the filter. A torrent can match several:
+// a complete torrent that is uploading is Active, Seeding and Completed.
+func (f StatusFilter) Matches(item *TorrentItem) bool {
+	complete := item.Progress >= 1.0
+	switch f {
+	case FilterActive:
+		return !item.stopped && (item.DownloadRate > 0 || item.UploadRate > 0)
+	case FilterDownloading:
+		return !complete && !item.stopped && !item.MetadataOnly && item.Err == ""
+	case FilterSeeding:
+		return complete && !item.stopped
+	case FilterPaused:
+		return item.stopped || item.MetadataOnly
+	case FilterCompleted:
+		return complete
+	case FilterError:
+		return item.Err != ""
+	default:
+		return true
+	}
+}
+
+// torrentProblem returns what is wrong with a torrent that needs the user's attention, or
+// an empty string. A torrent that has written data but whose folder is gone has most
+// likely lost its drive.
+func torrentProblem(item *TorrentItem) string {
+	if item.Downloaded > 0 && item.SavePath != "" {
+		if _, err := os.Stat(item.SavePath); os.IsNotExist(err) {
+			return fmt.Sprintf("the save folder %s is missing", item.SavePath)
+		}
+	}
+	return ""
+}
+
+// statusImportance picks the theme color a torrent's status is shown in, so it stays
+// legible in both light and dark themes
+func statusImportance(item *TorrentItem) widget.Importance {
+	switch {
+	case item.Err != "":
+		return widget.DangerImportance
+	case item.Warning != "":
+		return widget.WarningImportance
+	case item.stopped || item.MetadataOnly:
+		return widget.LowImportance
+	case item.Progress >= 1.0:
+		return widget.SuccessImportance
+	default:
+		return widget.MediumImportance
+	}
+}
+
+// filterInfoHashes lists the info-hashes of the torrents matching filter and containing
+// query in their name or note, in queue order or, if byRatio, lowest share ratio first
+func filterInfoHashes(torrents map[string]*TorrentItem, order QueueOrder, filter StatusFilter, query string, byRatio bool) []string {
+	query = strings.TrimSpace(query)
+	hashes := make([]string, 0, len(torrents))
+	for _, hash := range sortedInfoHashes(torrents, order) {
+		item := torrents[hash]
+		if item == nil || !filter.Matches(item) {
+			continue
+		}
+		if query != "" && !matchesTorrent(item, query) {
+			continue
+		}
+		hashes = append(hashes, hash)
+	}
+	if byRatio {
+		sortByRatio(hashes, torrents)
+	}
+	return hashes
+}
+
+// filterBar is the row of status filter buttons above the library, with the search box
+type filterBar struct {
+	Content fyne.CanvasObject
+
+	// Filter is the chosen status filter and Search the typed text
+	Filter StatusFilter
+	Search string
+
+	// ByRatio lists the torrents with the lowest share ratio first instead of in queue order
+	ByRatio bool
+
+	// OnChanged is called after the filter or the search changes
+	OnChanged func()
+
+	buttons     map[StatusFilter]*widget.Button
+	searchInput *widget.Entry
+	ratioCheck  *widget.Check
+}
+
+// newFilterBar creates a filter bar showing filter
+func newFilterBar(filter StatusFilter) *filterBar {
+	b := &filterBar{Filter: filter, buttons: make(map[StatusFilter]*widget.Button)}
+
+	buttons := container.NewHBox()
+	for _, f := range StatusFilters {
+		button := widget.NewButton(f.String(), func() {
+			b.SetFilter(f)
+		})
+		b.buttons[f] = button
+		buttons.Add(button)
+	}
+
+	b.searchInput = widget.NewEntry()
+	b.searchInput.SetPlaceHolder("Filter by name or note")
+	b.searchInput.ActionItem = widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
+		b.searchInput.SetText("")
+	})
+	b.searchInput.OnChanged = func(text string) {
+		b.Search = text
+		if b.OnChanged != nil {
+			b.OnChanged()
+		}
+	}
+
+	b.ratioCheck = widget.NewCheck("Lowest ratio first", func(checked bool) {
+		b.ByRatio = checked
+		if b.OnChanged != nil {
+			b.OnChanged()
+		}
+	})
+
+	b.Content = container.NewBorder(nil, nil, buttons, b.ratioCheck, b.searchInput)
+	b.highlight()
+	return b
+}
+
+// SetFilter shows the torrents matching f
+func (b *filterBar) SetFilter(f StatusFilter) {
+	b.Filter = f
+	b.highlight()
+	if b.OnChanged != nil {
+		b.OnChanged()
+	}
+}
+
+// SetByRatio lists the torrents with the lowest share ratio first, or in queue order
+func (b *filterBar) SetByRatio(byRatio bool) {
+	b.ratioCheck.SetChecked(byRatio)
+}
+
+// Clear shows every torrent, emptying the search box
+func (b *filterBar) Clear() {
+	b.searchInput.SetText("")
+	b.SetFilter(FilterAll)
+}
+
+// Update shows how many torrents each filter matches
+func (b *filterBar) Update(torrents map[string]*TorrentItem) {
+	for _, f := range StatusFilters {
+		count := 0
+		for _, item := range torrents {
+			if item != nil && f.Matches(item) {
+				count++
+			}
+		}
+		b.buttons[f].SetText(fmt.Sprintf("%s (%d)", f.String(), count))
+	}
+}
+
+// highlight marks the button of the chosen filter
+func (b *filterBar) highlight() {
+	for f, button := range b.buttons {
+		if f == b.Filter {
+			button.Importance = widget.HighImportance
+		} else {
+			button.Importance = widget.MediumImportance
+		}
+		button.Refresh()
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// findTorrentFiles lists the .torrent files directly inside dir, such as the state folder
+// of another client, sorted by name
+func findTorrentFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0)
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.EqualFold(filepath.Ext(entry.Name()), ".torrent") {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// importResult is the outcome of importing one .torrent file
+type importResult struct {
+	Name     string
+	Err      error // Why the torrent couldn't be added
+	Skipped  bool  // Already in the list
+	Checked  bool  // Whether its existing data has been verified yet
+	Present  int64 // Bytes of verified data found
+	Size     int64
+	infoHash string
+}
+
+// String describes the result for the import report
+func (r importResult) String() string {
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("%s: couldn't add: %v", r.Name, r.Err)
+	case r.Skipped:
+		return fmt.Sprintf("%s: already in the list", r.Name)
+	case !r.Checked:
+		return fmt.Sprintf("%s: checking existing data...", r.Name)
+	case r.Size > 0:
+		return fmt.Sprintf("%s: %.1f%% complete (%s of %s)", r.Name,
+			float64(r.Present)*100/float64(r.Size), HumanReadableSize(r.Present), HumanReadableSize(r.Size))
+	default:
+		return fmt.Sprintf("%s: complete", r.Name)
+	}
+}
+
+// importReport lists how each torrent of an import is doing as their data is checked
+type importReport struct {
+	Content fyne.CanvasObject
+
+	results      []importResult
+	stopErr      error // Why the import gave up on the remaining files
+	summaryLabel *widget.Label
+	list         *widget.List
+}
+
+// newImportReport creates an empty report
+func newImportReport() *importReport {
+	r := &importReport{summaryLabel: widget.NewLabel("")}
+	r.summaryLabel.Wrapping = fyne.TextWrapWord
+	r.list = widget.NewList(
+		func() int {
+			return len(r.results)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Result")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if int(id) < len(r.results) {
+				obj.(*widget.Label).SetText(r.results[id].String())
+			}
+		},
+	)
+	r.Content = container.NewBorder(r.summaryLabel, nil, nil, nil, r.list)
+	return r
+}
+
+// Add records the result for another .torrent file
+func (r *importReport) Add(result importResult) {
+	r.results = append(r.results, result)
+	r.update()
+}
+
+// Checked records the verified data of an imported torrent
+func (r *importReport) Checked(infoHash string, present, size int64) {
+	for i := range r.results {
+		if r.results[i].infoHash == infoHash {
+			r.results[i].Checked = true
+			r.results[i].Present = present
+			r.results[i].Size = size
+		}
+	}
+	r.update()
+}
+
+// Stop records that the import gave up on the remaining files
+func (r *importReport) Stop(err error) {
+	r.stopErr = err
+	r.update()
+}
+
+// update refreshes the summary and the list
+func (r *importReport) update() {
+	migrated, skipped, failed, checking := 0, 0, 0, 0
+	for _, result := range r.results {
+		switch {
+		case result.Err != nil:
+			failed++
+		case result.Skipped:
+			skipped++
+		default:
+			migrated++
+			if !result.Checked {
+				checking++
+			}
+		}
+	}
+	summary := fmt.Sprintf("Migrated %d of %d torrent(s): %d already in the list, %d failed.",
+		migrated, len(r.results), skipped, failed)
+	if checking > 0 {
+		summary += fmt.Sprintf(" Checking existing data of %d...", checking)
+	}
+	if r.stopErr != nil {
+		summary += fmt.Sprintf(" Import %v.", r.stopErr)
+	}
+	r.summaryLabel.SetText(summary)
+	r.list.Refresh()
+}
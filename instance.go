@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// instanceGreeting starts every handoff so stray connections are ignored
+const instanceGreeting = "reed-instance-v1"
+
+// instancePortPath returns the file where the running instance records its handoff port
+func instancePortPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "instance.port"), nil
+}
+
+// forwardToRunningInstance passes args to an already running Reed, reporting whether one
+// accepted them. An empty args still asks the running instance to come to the front.
+func forwardToRunningInstance(args []string) bool {
+	path, err := instancePortPath()
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	port, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return false
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), time.Second)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	writer := bufio.NewWriter(conn)
+	writer.WriteString(instanceGreeting + "\n")
+	for _, arg := range args {
+		writer.WriteString(arg + "\n")
+	}
+	if err := writer.Flush(); err != nil {
+		return false
+	}
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		tcpConn.CloseWrite()
+	}
+
+	// Only a running Reed answers with the greeting, so a stale port file taken over by
+	// another program doesn't swallow the arguments
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	return err == nil && strings.TrimSpace(reply) == instanceGreeting
+}
+
+// listenForInstances accepts handoffs from later launches. handle is called from the
+// listener's goroutine with the arguments of each handoff.
+func listenForInstances(handle func(args []string)) (net.Listener, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := instancePortPath()
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+	}
+	if err == nil {
+		port := listener.Addr().(*net.TCPAddr).Port
+		err = os.WriteFile(path, []byte(strconv.Itoa(port)), 0644)
+	}
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+				scanner := bufio.NewScanner(conn)
+				if !scanner.Scan() || scanner.Text() != instanceGreeting {
+					return
+				}
+				args := make([]string, 0)
+				for scanner.Scan() {
+					if arg := strings.TrimSpace(scanner.Text()); arg != "" {
+						args = append(args, arg)
+					}
+				}
+				conn.Write([]byte(instanceGreeting + "\n"))
+				handle(args)
+			}(conn)
+		}
+	}()
+
+	return listener, nil
+}
+
+// stopListeningForInstances closes the handoff listener and removes its port file
+func stopListeningForInstances(listener net.Listener) {
+	listener.Close()
+	if path, err := instancePortPath(); err == nil {
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// StorageLocation is a file or folder where Reed keeps its state
+type StorageLocation struct {
+	Name string
+	Path string
+	Err  error // Why the path couldn't be worked out
+}
+
+// storageLocations lists where Reed keeps its settings, session and downloads, so they can
+// be found and backed up. Logs aren't listed as they are only kept in memory.
+func storageLocations(dataDir string) []StorageLocation {
+	location := func(name string, path func() (string, error)) StorageLocation {
+		p, err := path()
+		return StorageLocation{Name: name, Path: p, Err: err}
+	}
+	return []StorageLocation{
+		location("Settings", ConfigPath),
+		location("Session", SessionPath),
+		location("Torrent Files", TorrentFilesDir),
+		location("Activity", ActivityPath),
+		{Name: "Downloads", Path: dataDir},
+	}
+}
+
+// showLocationsDialog shows where Reed keeps its state, with a button to copy each path and
+// one to show it in the file manager
+func showLocationsDialog(w fyne.Window, dataDir string) {
+	form := widget.NewForm()
+	for _, loc := range storageLocations(dataDir) {
+		if loc.Err != nil {
+			form.Append(loc.Name, widget.NewLabel(fmt.Sprintf("Unknown: %v", loc.Err)))
+			continue
+		}
+
+		pathLabel := widget.NewLabel(loc.Path)
+		pathLabel.Wrapping = fyne.TextWrapBreak
+		copyButton := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), func() {
+			fyne.CurrentApp().Clipboard().SetContent(loc.Path)
+		})
+
+		// Folders are opened, files are shown selected in theirs
+		info, err := os.Stat(loc.Path)
+		openButton := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+			open := revealPath
+			if info.IsDir() {
+				open = openPath
+			}
+			if err := open(loc.Path); err != nil {
+				dialog.ShowError(fmt.Errorf("error opening %s: %v", loc.Path, err), w)
+			}
+		})
+		item := widget.NewFormItem(loc.Name,
+			container.NewBorder(nil, nil, nil, container.NewHBox(copyButton, openButton), pathLabel))
+		if err != nil {
+			openButton.Disable()
+			item.HintText = "Not created yet"
+		}
+		form.AppendItem(item)
+	}
+
+	note := widget.NewLabel("Back up the settings, session and torrent files together to keep your torrent list. " +
+		"Logs aren't saved to disk; see the Log tab.")
+	note.Wrapping = fyne.TextWrapWord
+
+	d := dialog.NewCustom("Storage Locations", "Close", container.NewVBox(form, note), w)
+	d.Resize(fyne.NewSize(640, 0))
+	d.Show()
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	alog "github.com/anacrolix/log"
+)
+
+// logLimit is how many lines the Log tab keeps
+const logLimit = 1000
+
+// logTimeFormat matches the timestamps of the standard logger
+const logTimeFormat = "2006/01/02 15:04:05"
+
+// LogLevel is the least severe kind of message that is logged
+type LogLevel string
+
+const (
+	LogLevelError LogLevel = "error"
+	LogLevelWarn  LogLevel = "warn"
+	LogLevelInfo  LogLevel = "info"
+	LogLevelDebug LogLevel = "debug"
+)
+
+// LogLevelNames lists the levels in the order they are offered in the UI
+var LogLevelNames = []string{"Errors", "Warnings", "Information", "Debug"}
+
+// String returns the name shown in the UI
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelError:
+		return "Errors"
+	case LogLevelInfo:
+		return "Information"
+	case LogLevelDebug:
+		return "Debug"
+	}
+	return "Warnings"
+}
+
+// ParseLogLevel converts a UI name back into a LogLevel
+func ParseLogLevel(name string) LogLevel {
+	switch name {
+	case "Errors":
+		return LogLevelError
+	case "Information":
+		return LogLevelInfo
+	case "Debug":
+		return LogLevelDebug
+	}
+	return LogLevelWarn
+}
+
+// rank orders the levels from the most to the least severe
+func (l LogLevel) rank() int {
+	switch l {
+	case LogLevelError:
+		return 0
+	case LogLevelInfo:
+		return 2
+	case LogLevelDebug:
+		return 3
+	}
+	return 1
+}
+
+// allows reports whether a message at level is logged
+func (l LogLevel) allows(level LogLevel) bool {
+	return level.rank() <= l.rank()
+}
+
+// anacrolixLevel returns the matching level of the client's logger
+func (l LogLevel) anacrolixLevel() alog.Level {
+	switch l {
+	case LogLevelError:
+		return alog.Error
+	case LogLevelInfo:
+		return alog.Info
+	case LogLevelDebug:
+		return alog.Debug
+	}
+	return alog.Warning
+}
+
+// messageLevel tells the level of one of Reed's own log messages. They are written with
+// the standard log package, which has no levels: errors start with "Error", warnings with
+// "Warning", and the rest are informational.
+func messageLevel(message string) LogLevel {
+	switch {
+	case strings.HasPrefix(message, "Error"):
+		return LogLevelError
+	case strings.HasPrefix(message, "Warning"):
+		return LogLevelWarn
+	}
+	return LogLevelInfo
+}
+
+// logBuffer keeps the most recent log lines for the Log tab. It may be written from any
+// goroutine.
+type logBuffer struct {
+	mu      sync.Mutex
+	lines   []string
+	written int // Lines written so far, to tell whether the tab is out of date
+}
+
+// add records a line, dropping the oldest beyond the limit, and copies it to stderr
+func (b *logBuffer) add(line string) {
+	fmt.Fprintln(os.Stderr, line)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = append(b.lines, line)
+	if len(b.lines) > logLimit {
+		b.lines = b.lines[len(b.lines)-logLimit:]
+	}
+	b.written++
+}
+
+// snapshot returns a copy of the lines, oldest first, and how many have been written
+func (b *logBuffer) snapshot() ([]string, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.lines...), b.written
+}
+
+// clear drops every line
+func (b *logBuffer) clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = nil
+	b.written++
+}
+
+// reedLogWriter is the output of the standard logger, dropping messages below its level
+type reedLogWriter struct {
+	level  LogLevel
+	buffer *logBuffer
+}
+
+// Write implements io.Writer
+func (w *reedLogWriter) Write(p []byte) (int, error) {
+	message := strings.TrimSuffix(string(p), "\n")
+	if w.level.allows(messageLevel(message)) {
+		w.buffer.add(time.Now().Format(logTimeFormat) + " " + message)
+	}
+	return len(p), nil
+}
+
+// clientLogHandler passes the anacrolix client's messages on to the log buffer
+type clientLogHandler struct {
+	buffer *logBuffer
+}
+
+// Handle implements anacrolix's log.Handler
+func (h clientLogHandler) Handle(r alog.Record) {
+	h.buffer.add(fmt.Sprintf("%s [%s %s] %s", time.Now().Format(logTimeFormat),
+		r.Level.LogString(), strings.Join(r.Names, "/"), r.Msg.String()))
+}
+
+// setupLogging sends Reed's own log messages at level or above to buffer, and returns a
+// logger for the anacrolix client that does the same with its messages
+func setupLogging(level LogLevel, buffer *logBuffer) alog.Logger {
+	log.SetFlags(0)
+	log.SetOutput(&reedLogWriter{level: level, buffer: buffer})
+
+	logger := alog.Default.WithFilterLevel(level.anacrolixLevel())
+	logger.Handlers = []alog.Handler{clientLogHandler{buffer: buffer}}
+	return logger
+}
+
+// logView shows the recent log lines of Reed and the anacrolix client
+type logView struct {
+	Content fyne.CanvasObject
+
+	buffer  *logBuffer
+	lines   []string
+	written int
+	list    *widget.List
+}
+
+// newLogView creates a view of buffer's lines, logged at level
+func newLogView(buffer *logBuffer, level LogLevel) *logView {
+	v := &logView{buffer: buffer, written: -1}
+	v.list = widget.NewList(
+		func() int {
+			return len(v.lines)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("Log line")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if int(id) < len(v.lines) {
+				obj.(*widget.Label).SetText(v.lines[id])
+			}
+		},
+	)
+
+	clearButton := widget.NewButtonWithIcon("Clear", theme.DeleteIcon(), func() {
+		v.buffer.clear()
+		v.Update()
+	})
+	levelLabel := widget.NewLabel(fmt.Sprintf("Level: %s (changed in Settings, applied on launch)", level))
+	levelLabel.Importance = widget.LowImportance
+
+	v.Content = container.NewBorder(
+		container.NewHBox(
+			widget.NewLabelWithStyle("Log", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			clearButton,
+			levelLabel,
+		),
+		nil, nil, nil,
+		v.list,
+	)
+	return v
+}
+
+// Update shows lines written since the last update, keeping the newest in view
+func (v *logView) Update() {
+	lines, written := v.buffer.snapshot()
+	if written == v.written {
+		return
+	}
+	v.lines, v.written = lines, written
+	v.list.Refresh()
+	v.list.ScrollToBottom()
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// ValidateMagnetLink checks that link is a magnet link with a BitTorrent info-hash
+func ValidateMagnetLink(link string) error {
+	link = strings.TrimSpace(link)
+	if !strings.HasPrefix(strings.ToLower(link), "magnet:") {
+		return fmt.Errorf("not a magnet link")
+	}
+	if _, err := metainfo.ParseMagnetUri(link); err != nil {
+		return fmt.Errorf("invalid magnet link: %v", err)
+	}
+	return nil
+}
+
+// ParseInfoHash reads an info-hash written as 40 hex characters or 32 base32 characters,
+// in either case
+func ParseInfoHash(s string) (metainfo.Hash, error) {
+	var h metainfo.Hash
+	s = strings.TrimSpace(s)
+
+	var decoded []byte
+	var err error
+	switch len(s) {
+	case 40:
+		decoded, err = hex.DecodeString(strings.ToLower(s))
+	case 32:
+		decoded, err = base32.StdEncoding.DecodeString(strings.ToUpper(s))
+	default:
+		return h, fmt.Errorf("an info-hash is 40 hex or 32 base32 characters, got %d", len(s))
+	}
+	if err != nil || len(decoded) != len(h) {
+		return h, fmt.Errorf("invalid info-hash %q", s)
+	}
+
+	copy(h[:], decoded)
+	return h, nil
+}
+
+// ValidateInfoHash is ParseInfoHash for use as an entry validator
+func ValidateInfoHash(s string) error {
+	_, err := ParseInfoHash(s)
+	return err
+}
+
+// MagnetFromInfoHash builds a magnet link that finds the torrent through the DHT
+func MagnetFromInfoHash(h metainfo.Hash) string {
+	return "magnet:?xt=urn:btih:" + h.HexString()
+}
+
+// torrentMagnetLink rebuilds a magnet link for a torrent in the list from its info-hash and
+// name. Trackers are left out, as those of private trackers carry the user's passkey, so
+// the link finds peers through the DHT.
+func torrentMagnetLink(infoHash metainfo.Hash, item *TorrentItem) string {
+	m := metainfo.Magnet{InfoHash: infoHash, DisplayName: item.Name}
+	return m.String()
+}
+
+// magnetLinks lists the magnet links of the torrents with hashes, one per line, in the
+// form Batch Add reads
+func magnetLinks(hashes []string, torrents map[string]*TorrentItem) string {
+	links := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		item := torrents[hash]
+		if item == nil || item.Handle == nil {
+			continue
+		}
+		links = append(links, torrentMagnetLink(item.Handle.InfoHash(), item))
+	}
+	return strings.Join(links, "\n")
+}
+
+// verifyMagnetInfo checks that the metadata fetched for a magnet link hashes to the
+// info-hash the link asked for. anacrolix already rejects metadata that doesn't, so a
+// mismatch means the metadata was mishandled somewhere along the way.
+func verifyMagnetInfo(requested metainfo.Hash, t *torrent.Torrent) error {
+	if got := t.InfoHash(); got != requested {
+		return fmt.Errorf("the torrent has info-hash %s, but the magnet link asked for %s", got.HexString(), requested.HexString())
+	}
+	mi := t.Metainfo()
+	if got := mi.HashInfoBytes(); got != requested {
+		return fmt.Errorf("the metadata hashes to %s, but the magnet link asked for %s", got.HexString(), requested.HexString())
+	}
+	return nil
+}
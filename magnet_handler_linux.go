@@ -0,0 +1,72 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// magnetDesktopFile is the name of the desktop entry that claims the magnet: scheme
+const magnetDesktopFile = "reed-magnet.desktop"
+
+// desktopEntryPath returns where the user's desktop entry for Reed is written
+func desktopEntryPath() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "applications", magnetDesktopFile), nil
+}
+
+// registerMagnetHandler writes a desktop entry for Reed and makes it the default for magnet: links
+func registerMagnetHandler() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	path, err := desktopEntryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	entry := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=Reed Torrent Client
+Exec="%s" %%u
+Terminal=false
+NoDisplay=true
+MimeType=x-scheme-handler/magnet;
+`, exe)
+	if err := os.WriteFile(path, []byte(entry), 0644); err != nil {
+		return err
+	}
+
+	if out, err := exec.Command("xdg-mime", "default", magnetDesktopFile, "x-scheme-handler/magnet").CombinedOutput(); err != nil {
+		return fmt.Errorf("xdg-mime: %v %s", err, out)
+	}
+	return nil
+}
+
+// unregisterMagnetHandler removes the desktop entry, which drops Reed as the magnet: handler
+func unregisterMagnetHandler() error {
+	path, err := desktopEntryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+package main
+
+import "errors"
+
+// errMagnetHandlerUnsupported explains why the handler can't be changed from inside the app
+var errMagnetHandlerUnsupported = errors.New("magnet links are registered through the app bundle on this platform, not from Reed's settings")
+
+// registerMagnetHandler isn't supported on this platform
+func registerMagnetHandler() error {
+	return errMagnetHandlerUnsupported
+}
+
+// unregisterMagnetHandler isn't supported on this platform
+func unregisterMagnetHandler() error {
+	return errMagnetHandlerUnsupported
+}
@@ -0,0 +1,84 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// magnetClassKey is the per-user registry key for the magnet: URL scheme
+const magnetClassKey = `Software\Classes\magnet`
+
+// magnetCommand returns the command that opens magnet: links in the Reed at exe
+func magnetCommand(exe string) string {
+	return fmt.Sprintf(`"%s" "%%1"`, exe)
+}
+
+// registerMagnetHandler makes Reed open magnet: links for the current user
+func registerMagnetHandler() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, magnetClassKey, registry.ALL_ACCESS)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+	if err := key.SetStringValue("", "URL:Magnet Protocol"); err != nil {
+		return err
+	}
+	if err := key.SetStringValue("URL Protocol", ""); err != nil {
+		return err
+	}
+
+	commandKey, _, err := registry.CreateKey(registry.CURRENT_USER, magnetClassKey+`\shell\open\command`, registry.ALL_ACCESS)
+	if err != nil {
+		return err
+	}
+	defer commandKey.Close()
+	return commandKey.SetStringValue("", magnetCommand(exe))
+}
+
+// unregisterMagnetHandler removes the registration made by registerMagnetHandler. The
+// keys are left alone once another application has taken over magnet: links.
+func unregisterMagnetHandler() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	commandKey, err := registry.OpenKey(registry.CURRENT_USER, magnetClassKey+`\shell\open\command`, registry.QUERY_VALUE)
+	if err == registry.ErrNotExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	command, _, err := commandKey.GetStringValue("")
+	commandKey.Close()
+	if err != nil && err != registry.ErrNotExist {
+		return err
+	}
+	if !strings.EqualFold(command, magnetCommand(exe)) {
+		return fmt.Errorf("magnet links are handled by another application (%s), which was left registered", command)
+	}
+
+	// Keys have to be deleted from the innermost out
+	for _, path := range []string{
+		magnetClassKey + `\shell\open\command`,
+		magnetClassKey + `\shell\open`,
+		magnetClassKey + `\shell`,
+		magnetClassKey,
+	} {
+		if err := registry.DeleteKey(registry.CURRENT_USER, path); err != nil && err != registry.ErrNotExist {
+			return err
+		}
+	}
+	return nil
+}
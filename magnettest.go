@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/storage"
+)
+
+// How long a magnet test waits: for the metadata to arrive, and then for connections to
+// more peers so the counts are representative
+const (
+	magnetTestTimeout = 60 * time.Second
+	magnetTestSettle  = 5 * time.Second
+)
+
+// MagnetTest is what a dry run of a magnet link found out about its torrent
+type MagnetTest struct {
+	GotInfo bool // Whether the metadata arrived before the timeout
+	Name    string
+	Size    int64
+	Files   []string // Each file's path and size
+	Peers   int
+	Seeds   int
+}
+
+// String summarizes the result for the test dialog
+func (r MagnetTest) String() string {
+	if !r.GotInfo {
+		return fmt.Sprintf("No metadata arrived within %s. Connected to %d peer(s), %d of them seeds.\n\n"+
+			"The torrent may be dead, or its peers unreachable right now.", magnetTestTimeout, r.Peers, r.Seeds)
+	}
+	return fmt.Sprintf("'%s' is alive: connected to %d peer(s), %d of them seeds.\n\n%s in %d file(s):\n%s",
+		r.Name, r.Peers, r.Seeds, HumanReadableSize(r.Size), len(r.Files), strings.Join(r.Files, "\n"))
+}
+
+// testMagnet fetches the metadata of spec and counts its peers without downloading any
+// data, then drops the torrent again. Like a metadata-only add, nothing is written but the
+// torrent is never tracked, and its storage goes in a temporary folder that is removed
+// afterwards. Closing cancel stops the test early.
+func testMagnet(client *torrent.Client, spec *torrent.TorrentSpec, cancel <-chan struct{}) (MagnetTest, error) {
+	var result MagnetTest
+
+	// Dropping a torrent that is already in the client would remove it from the list
+	if _, ok := client.Torrent(spec.InfoHash); ok {
+		return result, fmt.Errorf("this torrent is already in the list")
+	}
+
+	dir, err := os.MkdirTemp("", "reed-magnet-test-")
+	if err != nil {
+		return result, fmt.Errorf("error creating temporary folder: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	spec.Storage = newTorrentStorage(dir, DefaultSavePathTemplate, "", storage.NewMapPieceCompletion())
+
+	// Only drop a handle the test created, in case the torrent was added in the meantime
+	t, isNew, err := client.AddTorrentSpec(spec)
+	if err != nil {
+		return result, err
+	}
+	if !isNew {
+		return result, fmt.Errorf("this torrent is already in the list")
+	}
+	defer t.Drop()
+	t.DisallowDataDownload()
+	t.DisallowDataUpload()
+
+	// Wait for the metadata, then a little longer for more peers to connect
+	timeout := time.NewTimer(magnetTestTimeout)
+	defer timeout.Stop()
+	select {
+	case <-t.GotInfo():
+		result.GotInfo = true
+		select {
+		case <-time.After(magnetTestSettle):
+		case <-cancel:
+		}
+	case <-timeout.C:
+	case <-cancel:
+	}
+
+	stats := t.Stats()
+	result.Peers = stats.ActivePeers
+	result.Seeds = stats.ConnectedSeeders
+	if result.GotInfo {
+		result.Name = t.Name()
+		result.Size = t.Length()
+		for _, f := range t.Files() {
+			result.Files = append(result.Files, fmt.Sprintf("%s (%s)", f.DisplayPath(), HumanReadableSize(f.Length())))
+		}
+	}
+	return result, nil
+}
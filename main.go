@@ -3,8 +3,11 @@ package main
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,37 +15,103 @@ import (
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
+	"golang.org/x/time/rate"
 )
 
 // TorrentItem represents a torrent in our UI
 type TorrentItem struct {
-	Name         string
-	Size         int64
-	Downloaded   int64
-	Status       string
-	Progress     float64
-	Handle       *torrent.Torrent
-	DownloadRate int64      // Download rate in bytes per second
-	UploadRate   int64      // Upload rate in bytes per second
-	Peers        int        // Number of connected peers
-	Seeds        int        // Number of connected seeds
-	AddedAt      time.Time  // When the torrent was added
-	LastUpdate   time.Time  // Last time stats were updated
-	Files        []FileInfo // Information about files in the torrent
-	FileCount    int        // Number of files in the torrent
-	ETA          string     // Estimated time to completion
+	Name          string
+	Size          int64
+	Downloaded    int64
+	Status        string
+	Progress      float64
+	Handle        *torrent.Torrent
+	DownloadRate  int64           // Download rate in bytes per second
+	UploadRate    int64           // Upload rate in bytes per second
+	Peers         int             // Number of connected peers
+	Seeds         int             // Number of connected seeds
+	AddedAt       time.Time       // When the torrent was added
+	CompletedAt   time.Time       // When the torrent finished downloading, zero until then
+	Note          string          // Free-text note from the user
+	Err           string          // What is wrong with the torrent, empty while it is fine
+	Warning       string          // Inconsistency noticed while adding the torrent, such as a bad info-hash
+	LastUpdate    time.Time       // Last time stats were updated
+	Files         []FileInfo      // Information about files in the torrent
+	FileCount     int             // Number of files in the torrent
+	ETA           string          // Estimated time to completion
+	Category      string          // Category chosen when the torrent was added
+	SavePath      string          // Where the torrent's data is stored on disk
+	Checking      bool            // Whether existing data is being verified
+	MetadataOnly  bool            // Stopped after fetching metadata until the user starts it
+	Preallocate   bool            // Whether the files' full size is reserved on disk when starting
+	Trackers      [][]string      // Announce list, tier by tier
+	UploadSlots   int             // Upload slot limit of its own, 0 to use the global setting
+	Priority      TorrentPriority // Preference over the other torrents in the queue
+	QueuePosition int             // Place in the manual queue order
+	Queued        bool            // Waiting for an active download slot
+	Paused        bool            // Paused by the user, with no connections or transfers
+	connLimit     int             // Connection limit last applied to the handle
+	stopped       bool            // Whether transfers are stopped on the handle
+
+	// Recent speeds for the graph in the details panel, recorded only while it is shown
+	downloadHistory *speedHistory
+	uploadHistory   *speedHistory
+
+	// Torrent data sent and received across launches, for the share ratio, and the part of
+	// it from earlier launches
+	Transferred       TransferTotals
+	transferredBefore TransferTotals
+
+	// Why the torrent was paused after repeated errors, empty unless it was, and how many
+	// errors it had recently
+	AutoPaused string
+	errorCount errorCounter
+
+	// Seed connections kept at once, 0 meaning no cap, whether seeds are preferred near
+	// completion, and how many connections were dropped for either
+	MaxSeeds     int
+	PreferSeeds  bool
+	peersDropped int
 }
 
+// AddOptions holds the per-torrent choices made when adding a torrent
+type AddOptions struct {
+	Category      string // Category used for the {category} placeholder
+	SaveDir       string // Base directory for the save path template, defaults to the data dir
+	CheckExisting bool   // Verify data already on disk before downloading
+	CrossSeed     bool   // Use existing data stored under the torrent's name directly in SaveDir
+	MetadataOnly  bool   // Stop once the metadata arrives so files can be chosen first
+	Paused        bool   // Add without starting transfers until the user resumes it
+	Preallocate   bool   // Reserve the files' full size on disk instead of growing them sparsely
+
+	// OnChecked is called on the UI thread once existing data has been verified. It
+	// replaces the dialog that reports the result of a cross-seed.
+	OnChecked func(item *TorrentItem)
+
+	// MagnetHash is the info-hash asked for by a magnet link, checked against the metadata
+	// once it arrives. It is zero for torrents not added from a magnet link.
+	MagnetHash metainfo.Hash
+}
+
+// noCategory is shown in the category selector for uncategorized torrents
+const noCategory = "(None)"
+
 // FileInfo represents a file within a torrent
 type FileInfo struct {
 	Path     string
 	Size     int64
 	Progress float64
+	Selected bool         // Whether the file should be downloaded
+	Priority FilePriority // How eagerly a selected file is downloaded
+	Checking bool         // Whether the file's pieces are being rechecked
+	Alias    string       // Name shown instead of the file's own, leaving the file on disk alone
 }
 
 // HumanReadableSize converts bytes to a human-readable string
@@ -87,13 +156,29 @@ func HumanReadableRate(bytesPerSec int64) string {
 }
 
 func main() {
+	// Hand the arguments to an already running Reed instead of starting a second client
+	if forwardToRunningInstance(os.Args[1:]) {
+		return
+	}
+
 	// Create a new Fyne application with ID
 	a := app.NewWithID("com.github.reed.torrentclient")
 	w := a.NewWindow("Reed Torrent Client")
 	w.Resize(fyne.NewSize(800, 600))
 
+	// Load the user's settings
+	appConfig, err := LoadConfig()
+	if err != nil {
+		log.Printf("Error loading settings, using defaults: %v", err)
+	}
+
+	// Log Reed's and the client's messages at the chosen level, keeping them for the Log tab
+	logs := &logBuffer{}
+	clientLogger := setupLogging(appConfig.LogLevel, logs)
+
 	// Create a torrent client
 	cfg := torrent.NewDefaultClientConfig()
+	cfg.Logger = clientLogger
 	// Set the download directory to the user's Downloads folder
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -106,17 +191,93 @@ func main() {
 		log.Fatalf("Error creating download directory: %v", err)
 	}
 
+	// Share one piece completion database between the per-torrent storage created on add
+	pieceCompletion := newPieceCompletion(cfg.DataDir)
+	defer pieceCompletion.Close()
+	cfg.DefaultStorage = newTorrentStorage(cfg.DataDir, EffectiveSavePathTemplate(appConfig.SavePathTemplate), "", pieceCompletion)
+
+	// Rate limiters adjusted at runtime by the speed limit settings and schedule
+	downloadLimiter := rate.NewLimiter(rate.Inf, 1<<16)
+	uploadLimiter := rate.NewLimiter(rate.Inf, 256<<10)
+	cfg.DownloadRateLimiter = downloadLimiter
+	cfg.UploadRateLimiter = uploadLimiter
+
+	// Use the chosen IP versions
+	applyIPStack(cfg, appConfig.IPStack)
+
+	// Bind to the chosen interface or address. If it can't be found, the kill-switch keeps the
+	// client offline rather than letting it connect from any interface.
+	var bind BindAddress
+	var bindErr error
+	offline := false
+	if appConfig.BindInterface != "" {
+		bind, bindErr = resolveBindAddress(appConfig.BindInterface)
+		switch {
+		case bindErr == nil:
+			applyBinding(cfg, bind)
+		case appConfig.KillSwitch:
+			log.Printf("Error binding to %s, staying offline: %v", appConfig.BindInterface, bindErr)
+			applyOffline(cfg)
+			offline = true
+		default:
+			log.Printf("Error binding to %s, using any interface: %v", appConfig.BindInterface, bindErr)
+		}
+	}
+	// Send traffic through the proxy, if there is one
+	applyProxy(cfg, appConfig.Proxy)
+
 	client, err := torrent.NewClient(cfg)
 	if err != nil {
 		log.Fatalf("Error creating torrent client: %v", err)
 	}
 	defer client.Close()
 
+	// Add the peer dialers that replace anacrolix's own TCP sockets
+	switch {
+	case offline:
+	case appConfig.Proxy.Enabled():
+		if err := addProxyDialer(client, appConfig.Proxy, bind); err != nil {
+			log.Fatalf("Error setting up proxy: %v", err)
+		}
+	case bind.Bound():
+		listeners, err := addBoundTCP(client, cfg, bind)
+		if err != nil {
+			log.Printf("Error setting up bound TCP connections: %v", err)
+		}
+		for _, l := range listeners {
+			defer l.Close()
+		}
+	}
+
+	// Reed's own HTTP requests take the same route as the client's
+	httpTransport := newHTTPTransport(appConfig.Proxy, bind)
+
 	// Create a list of torrents
 	torrentList := make(map[string]*TorrentItem)
 
-	// Track the selected index
-	selectedIndex := -1
+	// Save paths in use, so no two torrents are given the same one
+	savePaths := newSavePathReservations()
+
+	// Track the selected torrent by info-hash, so changes to the list can't make the
+	// selection point at another torrent, and the row it is shown in
+	selectedHash := ""
+	selectedRow := -1
+
+	// Whether the kill-switch has paused everything because the bound interface is down
+	killSwitchTripped := offline
+
+	// Helper function to tell whether Reed's own web requests must wait, as they shouldn't
+	// leave from another interface while the kill-switch holds everything back
+	offlineError := func() error {
+		if killSwitchTripped {
+			return fmt.Errorf("offline until %s is available", appConfig.BindInterface)
+		}
+		return nil
+	}
+
+	// Whether everything is paused because the battery is low, and the last charge read
+	batteryPaused := false
+	var battery BatteryStatus
 
 	// Helper function to validate torrent items and clean up invalid ones
 	validateTorrents := func() {
@@ -131,6 +292,7 @@ func main() {
 		// Remove invalid torrents
 		for _, hash := range invalidTorrents {
 			delete(torrentList, hash)
+			savePaths.Release(hash)
 		}
 	}
 
@@ -141,16 +303,58 @@ func main() {
 	// Variable to reference the add torrent dialog
 	var addTorrentDialog dialog.Dialog
 
+	// Removal actions, defined below so the list's context menu can use them
+	var confirmRemoveTorrent func(item *TorrentItem)
+	var quickRemoveTorrent func(item *TorrentItem)
+
+	// Repair action, defined below so the list's context menu can use it
+	var repairTorrent func(item *TorrentItem)
+
+	// Priority action, defined below with the other per-torrent settings
+	var setTorrentPriority func(item *TorrentItem, priority TorrentPriority)
+
+	// Pause action, defined below so the list's context menu can use it
+	var setTorrentPaused func(item *TorrentItem, paused bool)
+
+	// Shows a torrent in the list, defined below so adding a torrent twice can use it
+	var selectTorrent func(infoHash string)
+
+	// Helper function to copy the magnet links of torrents to the clipboard, one per line,
+	// ready to paste into Batch Add on another machine
+	copyMagnetLinks := func(hashes []string) {
+		links := magnetLinks(hashes, torrentList)
+		if links == "" {
+			dialog.ShowInformation("Copy Magnet Links", "There are no torrents to copy.", w)
+			return
+		}
+		a.Clipboard().SetContent(links)
+		dialog.ShowInformation("Copy Magnet Links", fmt.Sprintf("Copied %d magnet link(s) to the clipboard, one per line. "+
+			"Paste them into Batch Add to add the torrents elsewhere.", strings.Count(links, "\n")+1), w)
+	}
+
+	// Column width reset, defined below with the Files and Peers tabs so Settings can use it
+	var applyColumnWidths func()
+
+	// Status filter and search above the library, remembering the filter between launches
+	libraryFilter := newFilterBar(appConfig.LibraryFilter)
+
+	// Helper function to list the torrents the library shows, in queue or ratio order
+	libraryHashes := func() []string {
+		return filterInfoHashes(torrentList, appConfig.QueueOrder, libraryFilter.Filter, libraryFilter.Search, libraryFilter.ByRatio)
+	}
+
 	// Torrent list widget
 	list := widget.NewList(
 		func() int {
-			return len(torrentList)
+			return len(libraryHashes())
 		},
 		func() fyne.CanvasObject {
-			return container.NewVBox(
+			return newContextRow(container.NewVBox(
 				container.NewHBox(
 					widget.NewIcon(theme.FileIcon()),
 					widget.NewLabel("Torrent Name"),
+					widget.NewIcon(theme.DocumentIcon()),
+					widget.NewIcon(theme.WarningIcon()),
 				),
 				widget.NewProgressBar(),
 				container.NewHBox(
@@ -162,36 +366,92 @@ func main() {
 					widget.NewSeparator(),
 					widget.NewLabel("Speed:"),
 					widget.NewLabel("Speed"),
+					widget.NewSeparator(),
+					widget.NewLabel("Priority:"),
+					widget.NewLabel("Priority"),
 				),
-			)
+			))
 		},
 		func(id widget.ListItemID, item fyne.CanvasObject) {
-			// Convert the map to a slice for indexed access
-			torrents := make([]*TorrentItem, 0, len(torrentList))
-			for _, t := range torrentList {
-				torrents = append(torrents, t)
-			}
+			// Rows are shown in queue order
+			hashes := libraryHashes()
 
 			// Safety check for index bounds
-			if int(id) >= len(torrents) {
+			if int(id) >= len(hashes) {
 				return
 			}
 
 			// Get the torrent item at this index
-			torrentItem := torrents[id]
+			torrentItem := torrentList[hashes[id]]
 			if torrentItem == nil {
 				return
 			}
 
 			// Safe type assertions with fallbacks
-			vbox, ok := item.(*fyne.Container)
+			row, ok := item.(*contextRow)
+			if !ok {
+				return
+			}
+			vbox, ok := row.Content.(*fyne.Container)
 			if !ok || len(vbox.Objects) < 3 {
 				return
 			}
 
+			// Right click shows the actions for this torrent
+			row.OnTappedSecondary = func(e *fyne.PointEvent) {
+				priorityItems := make([]*fyne.MenuItem, 0, len(TorrentPriorityNames))
+				for _, name := range TorrentPriorityNames {
+					priority := ParseTorrentPriority(name)
+					menuItem := fyne.NewMenuItem(name, func() {
+						setTorrentPriority(torrentItem, priority)
+					})
+					menuItem.Checked = torrentItem.Priority.String() == name
+					priorityItems = append(priorityItems, menuItem)
+				}
+				priorityMenu := fyne.NewMenuItem("Priority", nil)
+				priorityMenu.ChildMenu = fyne.NewMenu("", priorityItems...)
+
+				pauseItem := fyne.NewMenuItem("Pause", func() {
+					setTorrentPaused(torrentItem, true)
+				})
+				if torrentItem.Paused {
+					pauseItem = fyne.NewMenuItem("Resume", func() {
+						setTorrentPaused(torrentItem, false)
+					})
+				}
+
+				menu := fyne.NewMenu("",
+					pauseItem,
+					priorityMenu,
+					fyne.NewMenuItemSeparator(),
+					fyne.NewMenuItem("Open Folder", func() {
+						if err := openDataFolder(torrentItem); err != nil {
+							dialog.ShowError(fmt.Errorf("error opening folder: %v", err), w)
+						}
+					}),
+					fyne.NewMenuItem("Repair from Folder...", func() {
+						repairTorrent(torrentItem)
+					}),
+					fyne.NewMenuItem("Copy Magnet Link", func() {
+						a.Clipboard().SetContent(torrentMagnetLink(torrentItem.Handle.InfoHash(), torrentItem))
+					}),
+					fyne.NewMenuItem("Copy Magnet Links of Shown Torrents", func() {
+						copyMagnetLinks(libraryHashes())
+					}),
+					fyne.NewMenuItemSeparator(),
+					fyne.NewMenuItem("Remove...", func() {
+						confirmRemoveTorrent(torrentItem)
+					}),
+					fyne.NewMenuItem("Quick Remove", func() {
+						quickRemoveTorrent(torrentItem)
+					}),
+				)
+				widget.ShowPopUpMenuAtPosition(menu, w.Canvas(), e.AbsolutePosition)
+			}
+
 			// Top row with icon and name
 			hbox, ok := vbox.Objects[0].(*fyne.Container)
-			if !ok || len(hbox.Objects) < 2 {
+			if !ok || len(hbox.Objects) < 4 {
 				return
 			}
 
@@ -200,6 +460,18 @@ func main() {
 				return
 			}
 
+			// Marks torrents that have a note
+			noteIcon, ok := hbox.Objects[2].(*widget.Icon)
+			if !ok {
+				return
+			}
+
+			// Marks torrents with a warning, explained in the General tab
+			warningIcon, ok := hbox.Objects[3].(*widget.Icon)
+			if !ok {
+				return
+			}
+
 			// Progress bar
 			progressBar, ok := vbox.Objects[1].(*widget.ProgressBar)
 			if !ok {
@@ -208,7 +480,7 @@ func main() {
 
 			// Bottom row with stats
 			statsBox, ok := vbox.Objects[2].(*fyne.Container)
-			if !ok || len(statsBox.Objects) < 8 {
+			if !ok || len(statsBox.Objects) < 11 {
 				return
 			}
 
@@ -227,11 +499,28 @@ func main() {
 				return
 			}
 
+			priorityLabel, ok := statsBox.Objects[10].(*widget.Label)
+			if !ok {
+				return
+			}
+
 			// Set values safely
 			nameLabel.SetText(torrentItem.Name)
+			if torrentItem.Note != "" {
+				noteIcon.Show()
+			} else {
+				noteIcon.Hide()
+			}
+			if torrentItem.Warning != "" {
+				warningIcon.Show()
+			} else {
+				warningIcon.Hide()
+			}
 			progressBar.Value = torrentItem.Progress
+			statusLabel.Importance = statusImportance(torrentItem)
 			statusLabel.SetText(torrentItem.Status)
 			sizeLabel.SetText(HumanReadableSize(torrentItem.Size))
+			priorityLabel.SetText(torrentItem.Priority.String())
 
 			if torrentItem.DownloadRate > 0 {
 				speedLabel.SetText(HumanReadableRate(torrentItem.DownloadRate))
@@ -245,422 +534,2069 @@ func main() {
 
 	// Set up list selection
 	list.OnSelected = func(id widget.ListItemID) {
-		selectedIndex = int(id)
+		if hashes := libraryHashes(); int(id) < len(hashes) {
+			selectedHash = hashes[id]
+			selectedRow = int(id)
+		}
 	}
 
 	// Status bar for the bottom of the window (declared here so it can be accessed in the goroutine)
+	networkLabel := widget.NewLabel("")
+	speedLimitLabel := widget.NewLabel("")
+	swarmLabel := widget.NewLabel("Peers: 0 / Seeds: 0")
+	swarmSeparator := widget.NewSeparator()
+
+	// Download speed over the last minute, one sample per update
+	speedGraphHistory := newSpeedHistory(60)
+	speedGraph := newSparkline(speedGraphHistory)
+	speedGraphSeparator := widget.NewSeparator()
+
 	statusBar := container.NewHBox(
 		widget.NewLabel("Status: Ready"),
 		widget.NewSeparator(),
 		widget.NewLabel(fmt.Sprintf("Download Directory: %s", cfg.DataDir)),
+		widget.NewSeparator(),
+		networkLabel,
+		widget.NewSeparator(),
+		speedLimitLabel,
+		swarmSeparator,
+		swarmLabel,
+		speedGraphSeparator,
+		container.NewCenter(speedGraph),
 	)
 
-	// Create a detail panel for the selected torrent
-	var detailsContainer *fyne.Container
-	detailsContainer = container.NewVBox(
-		widget.NewLabel("No torrent selected"),
-	)
+	// Helper function to show how the client reaches the network, including the binding and
+	// whether the kill-switch has paused everything
+	updateNetworkLabel := func() {
+		text := networkStatus(client.ListenAddrs(), appConfig.Proxy)
+		switch {
+		case offline:
+			text = fmt.Sprintf("Network: offline, %s not found", appConfig.BindInterface)
+		case bind.Bound():
+			text += ", bound to " + bind.String()
+		case bindErr != nil:
+			text += fmt.Sprintf(", %s not found", appConfig.BindInterface)
+		}
+		if killSwitchTripped {
+			text += " (kill-switch: all torrents paused)"
+		}
+		networkLabel.SetText(text)
+	}
+	updateNetworkLabel()
+
+	// Helper function to show or hide the optional parts of the status bar as configured
+	applyStatusBarVisibility := func() {
+		if appConfig.ShowSwarmTotals {
+			swarmSeparator.Show()
+			swarmLabel.Show()
+		} else {
+			swarmSeparator.Hide()
+			swarmLabel.Hide()
+		}
+		if appConfig.ShowSpeedGraph {
+			speedGraphSeparator.Show()
+			speedGraph.Show()
+		} else {
+			speedGraphSeparator.Hide()
+			speedGraph.Hide()
+		}
+	}
+	applyStatusBarVisibility()
+
+	// Helper function to apply the speed limits in effect right now and show them in the status bar
+	applySpeedLimits := func() {
+		download, upload, alternative := appConfig.ActiveSpeedLimits(time.Now())
+		applyRateLimit(downloadLimiter, download)
+		applyRateLimit(uploadLimiter, upload)
+
+		text := fmt.Sprintf("Limit: ↓ %s ↑ %s", FormatSpeedLimit(download), FormatSpeedLimit(upload))
+		if alternative {
+			text += " (alternative)"
+		}
+		speedLimitLabel.SetText(text)
+	}
+	applySpeedLimits()
 
 	// Function to update the details panel will be defined later in the code
 	var updateDetailsPanel func()
 
-	// Create a toolbar with action buttons
-	toolbar := widget.NewToolbar(
-		widget.NewToolbarAction(theme.ContentAddIcon(), func() {
-			// Create a larger, more functional add torrent dialog
+	// Function to show the add torrent dialog, defined below so the empty state can use it
+	var showAddDialog func()
+
+	// Header above the library list
+	libraryHeader := widget.NewLabelWithStyle("0 Torrents", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+
+	// Warning shown next to the header while the all-time ratio is below the minimum. It
+	// lists the torrents with the lowest ratio first, which most need seeding.
+	ratioBadge := widget.NewButtonWithIcon("", theme.WarningIcon(), func() {
+		libraryFilter.SetByRatio(true)
+	})
+	ratioBadge.Importance = widget.WarningImportance
+	ratioBadge.Hide()
+
+	// Empty state shown in place of the list until the first torrent is added
+	emptyState := container.NewCenter(container.NewVBox(
+		widget.NewIcon(theme.DownloadIcon()),
+		widget.NewLabelWithStyle("No torrents yet — click + to add one, or drag a .torrent here",
+			fyne.TextAlignCenter, fyne.TextStyle{}),
+		container.NewCenter(widget.NewButtonWithIcon("Add Torrent", theme.ContentAddIcon(), func() {
+			showAddDialog()
+		})),
+	))
+
+	// Helper function to refresh the library list along with its header and empty state
+	refreshLibrary := func() {
+		libraryHeader.SetText(newLibrarySummary(torrentList).String())
+		libraryFilter.Update(torrentList)
+		if len(torrentList) == 0 {
+			emptyState.Show()
+			list.Hide()
+		} else {
+			emptyState.Hide()
+			list.Show()
+		}
+		list.Refresh()
+
+		// Keep the highlight on the selected torrent when rows move, and drop it when the
+		// torrent is gone
+		if selectedHash != "" {
+			if row := selectionRow(selectedHash, libraryHashes()); row < 0 {
+				selectedHash = ""
+				selectedRow = -1
+				list.UnselectAll()
+			} else if row != selectedRow {
+				list.Select(row)
+			}
+		}
+	}
 
-			// Create a tab container for different ways to add torrents
-			magnetInput.SetPlaceHolder("Enter magnet link or torrent URL")
-			magnetInput.SetText("")
+	// Feed of recent events across all torrents, restored from the last launch if kept
+	var savedActivity []ActivityEvent
+	if appConfig.KeepActivity {
+		if savedActivity, err = LoadActivity(); err != nil {
+			log.Printf("Error loading activity: %v", err)
+		}
+	}
+	activity := newActivityFeed(savedActivity)
+	activity.HasTorrent = func(infoHash string) bool {
+		_, ok := torrentList[infoHash]
+		return ok
+	}
+	activity.OnChanged = func() {
+		if !appConfig.KeepActivity {
+			return
+		}
+		if err := SaveActivity(activity.Events); err != nil {
+			log.Printf("Error saving activity: %v", err)
+		}
+	}
 
-			// Create a multi-line text area for batch adding magnet links
-			batchInput := widget.NewMultiLineEntry()
-			batchInput.SetPlaceHolder("Enter multiple magnet links, one per line")
+	// Helper function to add an event to the activity feed
+	recordActivity := func(kind ActivityKind, infoHash, name, message string) {
+		activity.Add(ActivityEvent{
+			Time:     time.Now(),
+			Kind:     kind,
+			InfoHash: infoHash,
+			Name:     name,
+			Message:  message,
+		})
+	}
 
-			addButton := widget.NewButton("Add Torrent", func() {
-				magnetLink := magnetInput.Text
-				if magnetLink == "" {
-					dialog.ShowError(fmt.Errorf("please enter a magnet link"), w)
-					return
-				}
+	// Data sent and received by every torrent in earlier launches, read with the session
+	var transferredBefore TransferTotals
 
-				// Add the torrent
-				t, err := client.AddMagnet(magnetLink)
-				if err != nil {
-					dialog.ShowError(fmt.Errorf("error adding torrent: %v", err), w)
-					return
-				}
+	// Helper function to add up the data sent and received by every torrent ever added
+	allTimeTransferred := func() TransferTotals {
+		return transferredBefore.Add(newTransferTotals(client.Stats().ConnStats))
+	}
 
-				// Wait for info
-				go func() {
-					<-t.GotInfo()
+	// Whether a session file that couldn't be loaded is still in place, so saving would
+	// replace the torrents in it with an empty list
+	keepSessionFile := false
 
-					// Create a standardized torrent item
-					now := time.Now()
-					torrentItem := &TorrentItem{
-						Name:         t.Name(),
-						Size:         t.Length(),
-						Status:       "Downloading",
-						Handle:       t,
-						Progress:     0,
-						Downloaded:   0,
-						AddedAt:      now,
-						LastUpdate:   now,
-						DownloadRate: 0,
-						UploadRate:   0,
-						Peers:        0,
-						Seeds:        0,
-						FileCount:    len(t.Info().Files),
-						ETA:          "Calculating...",
-						Files:        []FileInfo{},
-					}
-
-					// Add to our list
-					torrentList[t.InfoHash().String()] = torrentItem
-
-					// Start downloading
-					t.DownloadAll()
-
-					// Update the UI safely from goroutine
-					fyne.Do(func() {
-						list.Refresh()
-						updateDetailsPanel()
-					})
-				}()
+	// Torrents still waiting for their metadata, by info-hash, which aren't in the list yet
+	pendingTorrents := make(map[string]PendingTorrent)
 
-				// Clear the input and close dialog
-				magnetInput.SetText("")
-				addTorrentDialog.Hide()
-			})
+	// Torrents from the last session that couldn't be restored, kept in the session so a
+	// missing drive or file doesn't lose them until the user forgets them
+	unrestored := make([]SessionTorrent, 0)
 
-			addBatchButton := widget.NewButton("Add All", func() {
-				// Get all lines from the batch input
-				magnetLinks := batchInput.Text
-				if magnetLinks == "" {
-					dialog.ShowError(fmt.Errorf("please enter at least one magnet link"), w)
-					return
-				}
+	// Helper function to save the torrent list so it can be restored on the next launch
+	saveSession := func() {
+		if keepSessionFile {
+			return
+		}
+		session := &Session{Version: sessionVersion, Torrents: []SessionTorrent{}, Transferred: allTimeTransferred()}
+		for hash, item := range torrentList {
+			if item == nil || item.Handle == nil {
+				continue
+			}
+			session.Torrents = append(session.Torrents, newSessionTorrent(hash, item))
+		}
+		for _, saved := range unrestored {
+			if _, ok := torrentList[saved.InfoHash]; !ok {
+				session.Torrents = append(session.Torrents, saved)
+			}
+		}
+		for hash, pending := range pendingTorrents {
+			if _, ok := torrentList[hash]; !ok {
+				session.Pending = append(session.Pending, pending)
+			}
+		}
+		sort.Slice(session.Pending, func(i, j int) bool {
+			return session.Pending[i].AddedAt.Before(session.Pending[j].AddedAt)
+		})
 
-				// Split by newlines
-				links := strings.Split(magnetLinks, "\n")
-				addedCount := 0
+		// Keep the file stable between saves
+		sort.Slice(session.Torrents, func(i, j int) bool {
+			return session.Torrents[i].AddedAt.Before(session.Torrents[j].AddedAt)
+		})
 
-				for _, link := range links {
-					link = strings.TrimSpace(link)
-					if link == "" {
-						continue
-					}
+		if err := session.Save(); err != nil {
+			log.Printf("Error saving session: %v", err)
+		}
+	}
 
-					// Add each torrent
-					t, err := client.AddMagnet(link)
-					if err != nil {
-						log.Printf("Error adding torrent: %v", err)
-						continue
-					}
+	// Each torrent's share of the global connection limit, 0 meaning unlimited. anacrolix
+	// only limits connections per torrent, so the update loop divides the global limit
+	// between the torrents that are allowed connections.
+	connShare := 0
+
+	// Helper function to apply a torrent's connection limit, scaled by its priority. anacrolix
+	// has no separate unchoke limit: it uploads to every connected peer that wants data, so a
+	// complete torrent uses one slot per connection and its connections are capped instead.
+	// While the kill-switch has tripped, the battery is low or the torrent is paused or
+	// queued, it is allowed no connections or transfers.
+	applyConnLimit := func(item *TorrentItem) {
+		limit := item.Priority.connLimit(cfg.EstablishedConnsPerTorrent)
+		if slots := effectiveUploadSlots(item, appConfig); slots > 0 && item.Progress >= 1.0 {
+			limit = slots
+		}
+		if connShare > 0 {
+			limit = min(limit, connShare)
+		}
+		stopped := killSwitchTripped || batteryPaused || item.Paused || item.Queued
+		if stopped {
+			limit = 0
+		}
+		// A torrent that starts stopped wants a limit of 0, the zero value, so the limit is
+		// applied whenever the torrent stops or starts as well as when it changes
+		changed := item.stopped != stopped
+		if changed {
+			if stopped {
+				item.Handle.DisallowDataDownload()
+				item.Handle.DisallowDataUpload()
+			} else {
+				item.Handle.AllowDataDownload()
+				item.Handle.AllowDataUpload()
+			}
+			item.stopped = stopped
+		}
+		if changed || item.connLimit != limit {
+			item.Handle.SetMaxEstablishedConns(limit)
+			item.connLimit = limit
+		}
+	}
 
-					// Process in background
-					go func(torrent *torrent.Torrent) {
-						<-torrent.GotInfo()
-
-						// Create a standardized torrent item
-						now := time.Now()
-						torrentItem := &TorrentItem{
-							Name:         torrent.Name(),
-							Size:         torrent.Length(),
-							Status:       "Downloading",
-							Handle:       torrent,
-							Progress:     0,
-							Downloaded:   0,
-							AddedAt:      now,
-							LastUpdate:   now,
-							DownloadRate: 0,
-							UploadRate:   0,
-							Peers:        0,
-							Seeds:        0,
-							FileCount:    len(torrent.Info().Files),
-							ETA:          "Calculating...",
-							Files:        []FileInfo{},
-						}
+	// Helper function to change a torrent's priority, which moves it in the queue
+	setTorrentPriority = func(item *TorrentItem, priority TorrentPriority) {
+		item.Priority = priority
+		applyConnLimit(item)
+		refreshLibrary()
+		saveSession()
+	}
 
-						torrentList[torrent.InfoHash().String()] = torrentItem
+	// Helper function to pause a torrent or resume it
+	setTorrentPaused = func(item *TorrentItem, paused bool) {
+		item.Paused = paused
+		if !paused {
+			item.AutoPaused = ""
+			item.errorCount.Reset()
+		}
+		applyConnLimit(item)
+		refreshLibrary()
+		updateDetailsPanel()
+		saveSession()
+	}
 
-						// Start downloading
-						torrent.DownloadAll()
+	// Helper function to count an error of a torrent, pausing it once its recent errors reach
+	// the limit so a broken torrent doesn't keep failing. The user is told once.
+	recordTorrentError := func(item *TorrentItem, err error) {
+		log.Printf("Error in %s: %v", item.Name, err)
+		limit := appConfig.ErrorPauseLimit
+		if count := item.errorCount.Add(time.Now()); limit <= 0 || item.Paused || count < float64(limit) {
+			return
+		}
+		item.AutoPaused = autoPauseReason(limit, err)
+		setTorrentPaused(item, true)
+		recordActivity(ActivityError, item.Handle.InfoHash().String(), item.Name,
+			fmt.Sprintf("Paused '%s' after %d errors: %v", item.Name, limit, err))
+		a.SendNotification(&fyne.Notification{
+			Title:   "Torrent Paused",
+			Content: fmt.Sprintf("%s was paused after repeated errors. Resume it once the problem is fixed.", item.Name),
+		})
+	}
 
-						// Update the UI safely from goroutine
-						fyne.Do(func() {
-							list.Refresh()
-							updateDetailsPanel()
-						})
-					}(t)
+	// Helper function to give torrents added without the Add dialog the default options
+	defaultAddOptions := func() AddOptions {
+		return AddOptions{CheckExisting: appConfig.CheckExistingFiles, Preallocate: appConfig.Preallocate}
+	}
 
-					addedCount++
-				}
+	// Helper function to reserve a torrent's disk space before it starts downloading, when
+	// it was added with preallocation. A failure is reported and the download goes ahead
+	// with sparse files.
+	preallocateTorrent := func(item *TorrentItem) {
+		if !item.Preallocate {
+			return
+		}
+		if err := preallocateFiles(item); err != nil {
+			log.Printf("Error preallocating %s: %v", item.Name, err)
+			fyne.Do(func() {
+				recordActivity(ActivityError, item.Handle.InfoHash().String(), item.Name,
+					fmt.Sprintf("Couldn't reserve disk space for '%s': %v", item.Name, err))
+				dialog.ShowInformation("Preallocation Failed",
+					fmt.Sprintf("Couldn't reserve disk space for '%s': %v\n\nIt will download without preallocation.", item.Name, err), w)
+			})
+		}
+	}
 
-				// Show success message
-				if addedCount > 0 {
-					dialog.ShowInformation("Torrents Added", fmt.Sprintf("Added %d torrent(s).", addedCount), w)
-				}
+	// Helper function to track a torrent in the list once its info arrives. saved is the
+	// torrent's state from the previous session, or nil for a newly added torrent.
+	trackTorrent := func(t *torrent.Torrent, opts AddOptions, saved *SessionTorrent, savePath func() string) {
+		go func() {
+			<-t.GotInfo()
+
+			// Create a standardized torrent item
+			now := time.Now()
+			torrentItem := &TorrentItem{
+				Name:         t.Name(),
+				Size:         t.Length(),
+				Status:       "Downloading",
+				Handle:       t,
+				Progress:     0,
+				Downloaded:   0,
+				AddedAt:      now,
+				LastUpdate:   now,
+				DownloadRate: 0,
+				UploadRate:   0,
+				Peers:        0,
+				Seeds:        0,
+				FileCount:    len(t.Info().Files),
+				ETA:          "Calculating...",
+				Files:        newFileInfos(t),
+				Category:     opts.Category,
+				SavePath:     savePath(),
+				MetadataOnly: opts.MetadataOnly,
+				Preallocate:  opts.Preallocate,
+				Paused:       opts.Paused,
+				Priority:     TorrentPriorityNormal,
+			}
 
-				// Clear the input and close dialog
-				batchInput.SetText("")
-				addTorrentDialog.Hide()
+			// Count failed writes, such as on a failing disk, instead of anacrolix's default of
+			// quietly stopping the download
+			t.SetOnWriteChunkError(func(err error) {
+				fyne.Do(func() {
+					recordTorrentError(torrentItem, fmt.Errorf("error writing data: %v", err))
+				})
 			})
 
-			// Create tabs for different ways to add torrents
-			tabs := container.NewAppTabs(
-				container.NewTabItem("Magnet Link", container.NewVBox(
-					widget.NewLabel("Enter magnet link or torrent URL:"),
-					magnetInput,
-					container.NewHBox(
-						layout.NewSpacer(),
-						widget.NewButton("Clear", func() {
-							magnetInput.SetText("")
-						}),
-						addButton,
-					),
-				)),
-				container.NewTabItem("Batch Add", container.NewVBox(
-					widget.NewLabel("Enter multiple magnet links (one per line):"),
-					container.NewVScroll(batchInput),
-					container.NewHBox(
-						layout.NewSpacer(),
-						widget.NewButton("Clear", func() {
-							batchInput.SetText("")
-						}),
-						addBatchButton,
-					),
-				)),
-			)
-
-			// Create dialog content
-			dialogContent := container.NewVBox(
-				tabs,
-			)
+			// Remember the announce list so edits to it can be saved
+			torrentItem.Trackers = torrentTrackers(t.Metainfo())
 
-			// Set minimum size for the dialog
-			dialogContent.Resize(fyne.NewSize(500, 300))
+			// Make sure a magnet link brought the torrent it asked for
+			if opts.MagnetHash != (metainfo.Hash{}) {
+				if err := verifyMagnetInfo(opts.MagnetHash, t); err != nil {
+					torrentItem.Warning = err.Error()
+					log.Printf("Warning: info-hash mismatch for %s: %v", t.Name(), err)
+					fyne.Do(func() {
+						recordActivity(ActivityError, t.InfoHash().String(), t.Name(),
+							fmt.Sprintf("Info-hash mismatch for '%s': %v", t.Name(), err))
+					})
+				}
+			}
 
-			// Create and show dialog
-			addTorrentDialog = dialog.NewCustom("Add Torrent", "Cancel", dialogContent, w)
-			addTorrentDialog.Resize(fyne.NewSize(500, 300))
-			addTorrentDialog.Show()
-		}),
-		widget.NewToolbarAction(theme.FolderOpenIcon(), func() {
-			fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
-				if err != nil {
-					dialog.ShowError(err, w)
-					return
+			if saved != nil {
+				// Bring back what the user chose last session
+				torrentItem.AddedAt = saved.AddedAt
+				torrentItem.CompletedAt = saved.CompletedAt
+				torrentItem.Files = mergeFileInfos(torrentItem.Files, saved.FileInfos())
+				torrentItem.UploadSlots = saved.UploadSlots
+				torrentItem.Note = saved.Note
+				torrentItem.QueuePosition = saved.QueuePosition
+				torrentItem.MaxSeeds = saved.MaxSeeds
+				torrentItem.PreferSeeds = saved.PreferSeeds
+				if torrentItem.Paused {
+					torrentItem.AutoPaused = saved.AutoPaused
 				}
-				if reader == nil {
-					return
+				torrentItem.transferredBefore = saved.Transferred
+				torrentItem.Transferred = saved.Transferred
+				if saved.Priority != "" {
+					torrentItem.Priority = saved.Priority
 				}
+			} else if err := SaveTorrentFile(t); err != nil {
+				log.Printf("Error saving torrent file for %s: %v", t.Name(), err)
+			}
 
-				// Read the torrent file
-				defer reader.Close()
+			// Add to our list on the UI goroutine, which owns it, putting a new torrent at the end
+			// of the queue and stopping a paused one before anything is downloaded. The file
+			// choices are copied for starting the download here, as the user may change them.
+			var files []FileInfo
+			metadataOnly := torrentItem.MetadataOnly
+			fyne.DoAndWait(func() {
+				if saved == nil {
+					torrentItem.QueuePosition = nextQueuePosition(torrentList)
+				}
 
-				// Get the file path from the URI
-				filePath := reader.URI().Path()
+				// Warn when the torrent's data is, or would have been, where another torrent's is
+				if torrentItem.Warning == "" {
+					torrentItem.Warning = savePathWarning(torrentItem, t.InfoHash().String(), torrentList,
+						savePaths.Overlapping(torrentItem.SavePath, t.InfoHash().String()))
+					if torrentItem.Warning != "" && saved == nil {
+						log.Printf("Warning: save path collision for %s: %s", t.Name(), torrentItem.Warning)
+						recordActivity(ActivityError, t.InfoHash().String(), t.Name(),
+							fmt.Sprintf("Save path collision for '%s': %s", t.Name(), torrentItem.Warning))
+					}
+				}
 
-				// Add the torrent
-				t, err := client.AddTorrentFromFile(filePath)
-				if err != nil {
-					dialog.ShowError(fmt.Errorf("error adding torrent: %v", err), w)
-					return
+				torrentList[t.InfoHash().String()] = torrentItem
+				delete(pendingTorrents, t.InfoHash().String())
+				unrestored = slices.DeleteFunc(unrestored, func(s SessionTorrent) bool {
+					return s.InfoHash == t.InfoHash().String()
+				})
+				if torrentItem.Paused {
+					applyConnLimit(torrentItem)
 				}
 
-				// Wait for info
-				go func() {
-					<-t.GotInfo()
+				// Hash any data that's already on disk so it counts as complete instead of being fetched again
+				if opts.CheckExisting {
+					torrentItem.Checking = true
+					torrentItem.Status = "Checking files"
+					refreshLibrary()
+					updateDetailsPanel()
+				}
+				files = slices.Clone(torrentItem.Files)
+			})
 
-					// Create a standardized torrent item
-					now := time.Now()
-					torrentItem := &TorrentItem{
-						Name:         t.Name(),
-						Size:         t.Length(),
-						Status:       "Downloading",
-						Handle:       t,
-						Progress:     0,
-						Downloaded:   0,
-						AddedAt:      now,
-						LastUpdate:   now,
-						DownloadRate: 0,
-						UploadRate:   0,
-						Peers:        0,
-						Seeds:        0,
-						FileCount:    len(t.Info().Files),
-						ETA:          "Calculating...",
-						Files:        []FileInfo{},
-					}
-
-					torrentList[t.InfoHash().String()] = torrentItem
-
-					// Start downloading
-					t.DownloadAll()
-
-					// Update the UI safely from goroutine
-					fyne.Do(func() {
-						list.Refresh()
-						updateDetailsPanel()
-					})
-				}()
-			}, w)
-			fd.SetFilter(storage.NewExtensionFileFilter([]string{".torrent"}))
-			fd.Show()
-		}),
-		widget.NewToolbarSeparator(),
-		widget.NewToolbarAction(theme.DeleteIcon(), func() {
-			if selectedIndex < 0 {
-				dialog.ShowInformation("Info", "Please select a torrent to remove", w)
-				return
-			}
+			if opts.CheckExisting {
+				t.VerifyData()
 
-			// Get the selected torrent safely using a slice
-			torrents := make([]*TorrentItem, 0, len(torrentList))
-			for _, t := range torrentList {
-				torrents = append(torrents, t)
-			}
+				// Record the verified bytes so existing data isn't reported as a fresh completion
+				downloaded := t.BytesCompleted()
+				fyne.DoAndWait(func() {
+					torrentItem.Downloaded = downloaded
+					torrentItem.Checking = false
+				})
+				log.Printf("Checked existing files for %s: %s of %s present",
+					t.Name(), HumanReadableSize(downloaded), HumanReadableSize(torrentItem.Size))
 
-			// Check index bounds
-			if selectedIndex >= len(torrents) {
-				dialog.ShowError(fmt.Errorf("invalid torrent selection"), w)
-				return
+				// Tell the user whether the cross-seed needs to download anything
+				if opts.OnChecked != nil {
+					fyne.Do(func() {
+						opts.OnChecked(torrentItem)
+					})
+				} else if opts.CrossSeed {
+					missing := torrentItem.Size - downloaded
+					fyne.Do(func() {
+						if missing <= 0 {
+							dialog.ShowInformation("Cross-Seed Verified",
+								fmt.Sprintf("All data for '%s' was found. It will be seeded without downloading anything.", t.Name()), w)
+						} else {
+							dialog.ShowInformation("Cross-Seed Incomplete",
+								fmt.Sprintf("%s of '%s' is missing or doesn't match and will be downloaded.",
+									HumanReadableSize(missing), t.Name()), w)
+						}
+					})
+				}
 			}
 
-			// Get the selected torrent
-			selectedTorrent := torrents[selectedIndex]
-
-			// Validate torrent
-			if selectedTorrent == nil {
-				dialog.ShowError(fmt.Errorf("selected torrent is invalid"), w)
-				return
-			}
+			// Start downloading the selected files, unless the user wants to choose them first
+			if !metadataOnly {
+				preallocateTorrent(torrentItem)
+				applyFileSelections(t, files)
 
-			// Validate handle
-			if selectedTorrent.Handle == nil {
-				dialog.ShowError(fmt.Errorf("torrent handle is invalid"), w)
-				// Clean up the invalid torrent
-				for hash, t := range torrentList {
-					if t == selectedTorrent {
-						delete(torrentList, hash)
-						break
+				// Warn about a new torrent that won't fit, while it can still be removed
+				if saved == nil {
+					if err := checkDiskSpace(torrentItem.SavePath, selectedRemaining(t, files)); err != nil {
+						fyne.Do(func() {
+							dialog.ShowInformation("Low Disk Space", fmt.Sprintf("'%s' may not fit: %v", t.Name(), err), w)
+						})
 					}
 				}
-				list.Refresh()
-				selectedIndex = -1
-				return
 			}
 
-			// Show confirmation dialog
-			confirmDialog := dialog.NewConfirm(
-				"Remove Torrent",
-				fmt.Sprintf("Are you sure you want to remove '%s'?", selectedTorrent.Name),
-				func(confirmed bool) {
-					if confirmed {
-						// Get hash before dropping the torrent (with safety check)
-						var hash string
-						if selectedTorrent.Handle != nil {
-							hash = selectedTorrent.Handle.InfoHash().String()
+			// Update the UI safely from goroutine
+			fyne.Do(func() {
+				if saved == nil {
+					recordActivity(ActivityAdded, t.InfoHash().String(), t.Name(), fmt.Sprintf("Added '%s'", t.Name()))
+				} else {
+					activity.Refresh()
+				}
+				refreshLibrary()
+				updateDetailsPanel()
+				saveSession()
+			})
+		}()
+	}
 
-							// Drop the torrent
-							selectedTorrent.Handle.Drop()
-						} else {
-							// If handle is nil, search for this torrent in the map to remove it
-							for h, t := range torrentList {
-								if t == selectedTorrent {
-									hash = h
-									break
-								}
-							}
-						}
+	// Info-hashes of magnet links being tested. Adding one would share the test's handle,
+	// which the test drops when it finishes.
+	testingHashes := make(map[metainfo.Hash]bool)
 
-						// Remove from our list
-						delete(torrentList, hash)
+	// Helper function to add a torrent using the save path layout
+	addTorrentSpec := func(spec *torrent.TorrentSpec, opts AddOptions) (*torrent.Torrent, error) {
+		if testingHashes[spec.InfoHash] {
+			return nil, fmt.Errorf("this torrent is being tested; add it once the test finishes")
+		}
+		baseDir := opts.SaveDir
+		if baseDir == "" {
+			baseDir = cfg.DataDir
+		}
 
-						// Update the UI
-						list.Refresh()
-						selectedIndex = -1
+		// Evaluate the template now so later settings changes don't move this torrent's data
+		tmpl := EffectiveSavePathTemplate(appConfig.SavePathTemplate)
 
-						// Update the details panel to show "No torrent selected"
-						updateDetailsPanel()
+		// Cross-seeded data sits where the other client put it, so ignore the template and
+		// always verify it before anything is downloaded
+		if opts.CrossSeed {
+			tmpl = DefaultSavePathTemplate
+			opts.CheckExisting = true
+		}
 
-						// Validate torrent list
-						validateTorrents()
-					}
-				}, w)
-			confirmDialog.Show()
-		}),
-		widget.NewToolbarSpacer(),
-		widget.NewToolbarAction(theme.SettingsIcon(), func() {
-			// Show settings dialog
-			dialog.ShowInformation("Settings", "Settings dialog will be implemented in a future update.", w)
-		}),
-		widget.NewToolbarAction(theme.HelpIcon(), func() {
-			dialog.ShowInformation("About Reed Torrent Client",
-				"Reed Torrent Client v1.0.0\n\nA lightweight torrent client built with Go using the anacrolix/torrent library and Fyne for the UI.", w)
-		}),
-	)
+		// Keep the data apart from any other torrent's, such as a different torrent with the
+		// same name, by adding a short info-hash to the path. Cross-seeds share on purpose.
+		// The path is reserved when the storage opens, so torrents whose metadata arrives at
+		// the same time can't both get it, and the list is given the same one.
+		contentPath := func(info *metainfo.Info, infoHash metainfo.Hash) string {
+			return savePaths.Reserve(infoHash, TemplateSavePath(baseDir, tmpl, opts.Category, info, infoHash),
+				len(info.Files) == 0, opts.CrossSeed)
+		}
+		spec.Storage = newFileStorage(baseDir, pieceCompletion, contentPath)
 
-	// The status bar is already declared above so we don't need to redeclare it here
+		t, isNew, err := client.AddTorrentSpec(spec)
+		if err != nil {
+			if _, ok := client.Torrent(spec.InfoHash); !ok {
+				savePaths.Release(spec.InfoHash.String())
+			}
+			recordActivity(ActivityError, "", spec.DisplayName, fmt.Sprintf("Couldn't add '%s': %v", spec.DisplayName, err))
+			return nil, err
+		}
 
-	// Function to update the details panel
-	updateDetailsPanel = func() {
-		// Clear the container
-		detailsContainer.Objects = nil
+		// Tracking a torrent that is already in the client again would replace its item and
+		// lose the user's choices, so show the one in the list instead
+		if !isNew {
+			selectTorrent(t.InfoHash().String())
+			return t, nil
+		}
 
-		if selectedIndex < 0 {
-			detailsContainer.Add(widget.NewLabel("No torrent selected"))
-			detailsContainer.Refresh()
-			return
+		// Keep a torrent without its metadata in the session until the metadata arrives
+		if t.Info() == nil {
+			pendingTorrents[t.InfoHash().String()] = newPendingTorrent(spec, opts)
+			saveSession()
 		}
 
-		// Get the selected torrent safely
-		var selectedTorrent *TorrentItem
+		trackTorrent(t, opts, nil, func() string {
+			return contentPath(t.Info(), t.InfoHash())
+		})
+		return t, nil
+	}
 
-		if selectedIndex >= 0 {
-			// Convert map to a slice for indexed access
-			torrents := make([]*TorrentItem, 0, len(torrentList))
-			for _, t := range torrentList {
-				torrents = append(torrents, t)
-			}
+	// Helper function to re-add a torrent saved in the previous session at its original save
+	// path, paused or not as given
+	restoreTorrent := func(saved SessionTorrent, paused bool) error {
+		path, err := torrentFilePath(saved.InfoHash)
+		if err != nil {
+			return err
+		}
+		mi, err := metainfo.LoadFromFile(path)
+		if err != nil {
+			return err
+		}
+		spec, err := torrent.TorrentSpecFromMetaInfoErr(mi)
+		if err != nil {
+			return err
+		}
+		if saved.SavePath == "" {
+			return fmt.Errorf("no save path recorded")
+		}
+		spec.Storage = newSavedTorrentStorage(saved.SavePath, pieceCompletion)
 
-			// Only access the slice if the index is valid
-			if selectedIndex < len(torrents) {
-				selectedTorrent = torrents[selectedIndex]
+		// An edited announce list replaces the one in the metainfo
+		if saved.Trackers != nil {
+			spec.Trackers = saved.Trackers
+		}
+
+		t, isNew, err := client.AddTorrentSpec(spec)
+		if err != nil {
+			if _, ok := client.Torrent(spec.InfoHash); !ok {
+				savePaths.Release(saved.InfoHash)
 			}
+			return err
 		}
 
-		if selectedTorrent == nil {
-			detailsContainer.Add(widget.NewLabel("Torrent not found or none selected"))
-			detailsContainer.Refresh()
-			return
+		// The torrent was added again in the meantime, so keep the user's new choices
+		if !isNew {
+			return nil
+		}
+		savePaths.Keep(saved.InfoHash, saved.SavePath)
+
+		opts := AddOptions{
+			Category:     saved.Category,
+			MetadataOnly: saved.MetadataOnly,
+			Preallocate:  saved.Preallocate,
+			Paused:       paused,
+		}
+		trackTorrent(t, opts, &saved, func() string {
+			return saved.SavePath
+		})
+		return nil
+	}
+
+	// Helper function to add a torrent from a magnet link
+	addMagnet := func(link string, opts AddOptions) (*torrent.Torrent, error) {
+		if err := ValidateMagnetLink(link); err != nil {
+			return nil, err
+		}
+		spec, err := torrent.TorrentSpecFromMagnetUri(strings.TrimSpace(link))
+		if err != nil {
+			return nil, err
+		}
+		if appConfig.VerifyMagnets {
+			opts.MagnetHash = spec.InfoHash
+		}
+		return addTorrentSpec(spec, opts)
+	}
+
+	// Helper function to check whether a magnet link's torrent is alive, fetching its
+	// metadata and counting peers without adding it
+	testMagnetLink := func(link string) {
+		if err := ValidateMagnetLink(link); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		spec, err := torrent.TorrentSpecFromMagnetUri(strings.TrimSpace(link))
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("error reading magnet link: %v", err), w)
+			return
+		}
+		if _, ok := torrentList[spec.InfoHash.String()]; ok {
+			dialog.ShowInformation("Test Magnet Link", "This torrent is already in the list.", w)
+			return
+		}
+
+		cancel := make(chan struct{})
+		cancelButton := widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), nil)
+		progressDialog := dialog.NewCustomWithoutButtons("Test Magnet Link", container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("Looking for peers and metadata, for up to %s...", magnetTestTimeout)),
+			widget.NewProgressBarInfinite(),
+		), w)
+		cancelButton.OnTapped = func() {
+			cancelButton.Disable()
+			close(cancel)
+		}
+		progressDialog.SetButtons([]fyne.CanvasObject{cancelButton})
+		progressDialog.Resize(fyne.NewSize(400, 150))
+		progressDialog.Show()
+
+		testingHashes[spec.InfoHash] = true
+		go func() {
+			result, err := testMagnet(client, spec, cancel)
+			if err == nil {
+				log.Printf("Tested magnet link %s: metadata %t, %d peer(s), %d seed(s)",
+					spec.InfoHash.HexString(), result.GotInfo, result.Peers, result.Seeds)
+			}
+			fyne.Do(func() {
+				delete(testingHashes, spec.InfoHash)
+				progressDialog.Hide()
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("error testing magnet link: %v", err), w)
+					return
+				}
+				resultLabel := widget.NewLabel(result.String())
+				resultLabel.Wrapping = fyne.TextWrapWord
+				resultDialog := dialog.NewCustom("Test Magnet Link", "Close", container.NewVScroll(resultLabel), w)
+				resultDialog.Resize(fyne.NewSize(550, 400))
+				resultDialog.Show()
+			})
+		}()
+	}
+
+	// Helper function to add a torrent from a .torrent file on disk
+	addTorrentFile := func(path string, opts AddOptions) (*torrent.Torrent, error) {
+		mi, err := metainfo.LoadFromFile(path)
+		if err != nil {
+			return nil, err
+		}
+		spec, err := torrent.TorrentSpecFromMetaInfoErr(mi)
+		if err != nil {
+			return nil, err
+		}
+		return addTorrentSpec(spec, opts)
+	}
+
+	// Helper function to add a torrent from a .torrent file on the web. The file is fetched
+	// in the background, and errors are shown once it fails.
+	addTorrentURL := func(link string, opts AddOptions) {
+		if err := offlineError(); err != nil {
+			dialog.ShowError(fmt.Errorf("error adding torrent from %s: %v", link, err), w)
+			return
+		}
+		httpClient := &http.Client{Transport: httpTransport, Timeout: time.Duration(appConfig.TorrentURLTimeout) * time.Second}
+		maxSize := int64(appConfig.TorrentURLMaxSize) << 20
+		go func() {
+			// Give up on retries if the kill-switch trips while the file is being fetched
+			mi, err := FetchTorrentURL(httpClient, link, maxSize, func() error {
+				var err error
+				fyne.DoAndWait(func() {
+					err = offlineError()
+				})
+				return err
+			})
+			fyne.Do(func() {
+				if err == nil {
+					var spec *torrent.TorrentSpec
+					spec, err = torrent.TorrentSpecFromMetaInfoErr(mi)
+					if err == nil {
+						_, err = addTorrentSpec(spec, opts)
+					}
+				}
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("error adding torrent from %s: %v", link, err), w)
+				}
+			})
+		}()
+	}
+
+	// Function to show a larger, more functional add torrent dialog
+	showAddDialog = func() {
+		// Create a tab container for different ways to add torrents
+		magnetInput.SetPlaceHolder("Enter magnet link or torrent URL")
+		magnetInput.SetText("")
+
+		// Create a multi-line text area for batch adding magnet links
+		batchInput := widget.NewMultiLineEntry()
+		batchInput.SetPlaceHolder("Enter multiple magnet links or info-hashes, one per line")
+
+		// Entry for adding a torrent by its info-hash alone, previewing the magnet link it becomes
+		infoHashInput := widget.NewEntry()
+		infoHashInput.SetPlaceHolder("40 hex or 32 base32 characters")
+		infoHashInput.Validator = ValidateInfoHash
+		infoHashPreview := widget.NewLabel("")
+		infoHashPreview.Wrapping = fyne.TextWrapBreak
+		infoHashInput.OnChanged = func(text string) {
+			if h, err := ParseInfoHash(text); err == nil {
+				infoHashPreview.SetText(MagnetFromInfoHash(h))
+			} else {
+				infoHashPreview.SetText("")
+			}
+		}
+
+		// Options shared by both ways of adding
+		categorySelect := widget.NewSelect(append([]string{noCategory}, appConfig.Categories...), nil)
+		categorySelect.SetSelected(noCategory)
+		saveDirInput := widget.NewEntry()
+		saveDirInput.SetPlaceHolder(cfg.DataDir)
+		checkExistingInput := widget.NewCheck("Check existing files", nil)
+		checkExistingInput.SetChecked(appConfig.CheckExistingFiles)
+		metadataOnlyInput := widget.NewCheck("Stop after metadata to choose files first", nil)
+		preallocateInput := widget.NewCheck("Preallocate disk space", nil)
+		preallocateInput.SetChecked(appConfig.Preallocate)
+
+		addOptions := func() AddOptions {
+			category := categorySelect.Selected
+			if category == noCategory {
+				category = ""
+			}
+			return AddOptions{
+				Category:      category,
+				SaveDir:       strings.TrimSpace(saveDirInput.Text),
+				CheckExisting: checkExistingInput.Checked,
+				MetadataOnly:  metadataOnlyInput.Checked,
+				Preallocate:   preallocateInput.Checked,
+			}
+		}
+
+		addButton := widget.NewButton("Add Torrent", func() {
+			magnetLink := magnetInput.Text
+			if magnetLink == "" {
+				dialog.ShowError(fmt.Errorf("please enter a magnet link"), w)
+				return
+			}
+
+			// Add the torrent, fetching .torrent files from the web in the background
+			if IsTorrentURL(magnetLink) {
+				addTorrentURL(magnetLink, addOptions())
+			} else if _, err := addMagnet(magnetLink, addOptions()); err != nil {
+				dialog.ShowError(fmt.Errorf("error adding torrent: %v", err), w)
+				return
+			}
+
+			// Clear the input and close dialog
+			magnetInput.SetText("")
+			addTorrentDialog.Hide()
+		})
+
+		addBatchButton := widget.NewButton("Add All", func() {
+			// Get all lines from the batch input
+			magnetLinks := batchInput.Text
+			if magnetLinks == "" {
+				dialog.ShowError(fmt.Errorf("please enter at least one magnet link"), w)
+				return
+			}
+
+			// Split by newlines, skipping blank lines
+			links := make([]string, 0)
+			for _, link := range strings.Split(magnetLinks, "\n") {
+				if link = strings.TrimSpace(link); link != "" {
+					links = append(links, link)
+				}
+			}
+			addedCount := 0
+			opts := addOptions()
+
+			// Give up on the rest once too many fail in a row
+			streak := newErrorStreak(appConfig.BatchErrorLimit)
+			for i, link := range links {
+				// Lines holding just an info-hash are looked up through the DHT
+				if h, err := ParseInfoHash(link); err == nil {
+					link = MagnetFromInfoHash(h)
+				}
+
+				// Add each torrent. Magnet links are added even without a network, only failing
+				// once their metadata never arrives, so being offline counts as a failure here.
+				err := offlineError()
+				if err == nil {
+					_, err = addMagnet(link, opts)
+				}
+				if err != nil {
+					log.Printf("Error adding torrent: %v", err)
+				} else {
+					addedCount++
+				}
+				if streak.Record(err) {
+					err := streak.Err(i+1, len(links))
+					log.Printf("Warning: batch add %v", err)
+					dialog.ShowError(fmt.Errorf("%v, %d added", err, addedCount), w)
+					return
+				}
+			}
+
+			// Show success message
+			if addedCount > 0 {
+				dialog.ShowInformation("Torrents Added", fmt.Sprintf("Added %d torrent(s).", addedCount), w)
+			}
+
+			// Clear the input and close dialog
+			batchInput.SetText("")
+			addTorrentDialog.Hide()
+		})
+
+		addInfoHashButton := widget.NewButton("Add Torrent", func() {
+			h, err := ParseInfoHash(infoHashInput.Text)
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+
+			// Add the torrent
+			if _, err := addMagnet(MagnetFromInfoHash(h), addOptions()); err != nil {
+				dialog.ShowError(fmt.Errorf("error adding torrent: %v", err), w)
+				return
+			}
+
+			// Clear the input and close dialog
+			infoHashInput.SetText("")
+			addTorrentDialog.Hide()
+		})
+
+		// Create tabs for different ways to add torrents
+		tabs := container.NewAppTabs(
+			container.NewTabItem("Magnet Link", container.NewVBox(
+				widget.NewLabel("Enter magnet link or torrent URL:"),
+				magnetInput,
+				container.NewHBox(
+					layout.NewSpacer(),
+					widget.NewButton("Clear", func() {
+						magnetInput.SetText("")
+					}),
+					widget.NewButton("Test", func() {
+						testMagnetLink(magnetInput.Text)
+					}),
+					addButton,
+				),
+			)),
+			container.NewTabItem("Batch Add", container.NewVBox(
+				widget.NewLabel("Enter multiple magnet links or info-hashes (one per line):"),
+				container.NewVScroll(batchInput),
+				container.NewHBox(
+					layout.NewSpacer(),
+					widget.NewButton("Clear", func() {
+						batchInput.SetText("")
+					}),
+					addBatchButton,
+				),
+			)),
+			container.NewTabItem("Info-Hash", container.NewVBox(
+				widget.NewLabel("Enter an info-hash; the torrent is found through the DHT:"),
+				infoHashInput,
+				infoHashPreview,
+				container.NewHBox(
+					layout.NewSpacer(),
+					widget.NewButton("Clear", func() {
+						infoHashInput.SetText("")
+					}),
+					addInfoHashButton,
+				),
+			)),
+		)
+
+		// Create dialog content
+		dialogContent := container.NewVBox(
+			tabs,
+			widget.NewForm(
+				widget.NewFormItem("Category", categorySelect),
+				widget.NewFormItem("Save To", saveDirInput),
+				widget.NewFormItem("", checkExistingInput),
+				widget.NewFormItem("", metadataOnlyInput),
+				widget.NewFormItem("", preallocateInput),
+			),
+		)
+
+		// Set minimum size for the dialog
+		dialogContent.Resize(fyne.NewSize(500, 300))
+
+		// Create and show dialog
+		addTorrentDialog = dialog.NewCustom("Add Torrent", "Cancel", dialogContent, w)
+		addTorrentDialog.Resize(fyne.NewSize(500, 380))
+		addTorrentDialog.Show()
+	}
+
+	// Function to add a torrent for cross-seeding data that is already on disk
+	showCrossSeedDialog := func() {
+		sourceInput := widget.NewEntry()
+		sourceInput.SetPlaceHolder("Magnet link or path to a .torrent file")
+		browseTorrentButton := widget.NewButtonWithIcon("", theme.FileIcon(), func() {
+			fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				if reader == nil {
+					return
+				}
+				defer reader.Close()
+				sourceInput.SetText(reader.URI().Path())
+			}, w)
+			fd.SetFilter(storage.NewExtensionFileFilter([]string{".torrent"}))
+			fd.Show()
+		})
+
+		dataDirInput := widget.NewEntry()
+		dataDirInput.SetPlaceHolder("Folder containing the existing download")
+		browseDataButton := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+			dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				if uri != nil {
+					dataDirInput.SetText(uri.Path())
+				}
+			}, w)
+		})
+
+		categorySelect := widget.NewSelect(append([]string{noCategory}, appConfig.Categories...), nil)
+		categorySelect.SetSelected(noCategory)
+
+		explanation := widget.NewLabel("The torrent's data is expected in the chosen folder under the torrent's name, " +
+			"exactly as another client saved it. It is checked before anything is downloaded: " +
+			"if every piece verifies, no new download will occur and the torrent just seeds.")
+		explanation.Wrapping = fyne.TextWrapWord
+
+		var crossSeedDialog dialog.Dialog
+		addButton := widget.NewButtonWithIcon("Add for Cross-Seed", theme.ConfirmIcon(), func() {
+			source := strings.TrimSpace(sourceInput.Text)
+			dataDir := strings.TrimSpace(dataDirInput.Text)
+			if source == "" {
+				dialog.ShowError(fmt.Errorf("please enter a magnet link or choose a .torrent file"), w)
+				return
+			}
+			if info, err := os.Stat(dataDir); dataDir == "" || err != nil || !info.IsDir() {
+				dialog.ShowError(fmt.Errorf("please choose the folder containing the existing data"), w)
+				return
+			}
+
+			category := categorySelect.Selected
+			if category == noCategory {
+				category = ""
+			}
+			opts := AddOptions{Category: category, SaveDir: dataDir, CrossSeed: true}
+
+			var err error
+			if strings.HasPrefix(source, "magnet:") {
+				_, err = addMagnet(source, opts)
+			} else {
+				_, err = addTorrentFile(source, opts)
+			}
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("error adding torrent: %v", err), w)
+				return
+			}
+			crossSeedDialog.Hide()
+		})
+		addButton.Importance = widget.HighImportance
+
+		content := container.NewVBox(
+			widget.NewForm(
+				widget.NewFormItem("Torrent", container.NewBorder(nil, nil, nil, browseTorrentButton, sourceInput)),
+				widget.NewFormItem("Existing Data", container.NewBorder(nil, nil, nil, browseDataButton, dataDirInput)),
+				widget.NewFormItem("Category", categorySelect),
+			),
+			explanation,
+			container.NewHBox(layout.NewSpacer(), addButton),
+		)
+
+		crossSeedDialog = dialog.NewCustom("Add for Cross-Seed", "Cancel", content, w)
+		crossSeedDialog.Resize(fyne.NewSize(550, 320))
+		crossSeedDialog.Show()
+	}
+
+	// Helper function to show the progress of an import as its torrents are checked
+	showImportReport := func(torrentDir, dataDir string) {
+		paths, err := findTorrentFiles(torrentDir)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("error reading %s: %v", torrentDir, err), w)
+			return
+		}
+		if len(paths) == 0 {
+			dialog.ShowInformation("Import", fmt.Sprintf("No .torrent files were found in %s.", torrentDir), w)
+			return
+		}
+
+		report := newImportReport()
+
+		// Helper to add one .torrent file, describing how it went
+		importFile := func(path string) importResult {
+			result := importResult{Name: filepath.Base(path)}
+			mi, err := metainfo.LoadFromFile(path)
+			if err != nil {
+				result.Err = err
+				return result
+			}
+			if info, err := mi.UnmarshalInfo(); err == nil {
+				result.Name = info.BestName()
+			}
+			result.infoHash = mi.HashInfoBytes().String()
+			if _, ok := torrentList[result.infoHash]; ok {
+				result.Skipped = true
+				return result
+			}
+
+			// Point the torrent at the existing data and verify it, like a cross-seed
+			opts := AddOptions{
+				SaveDir:   dataDir,
+				CrossSeed: true,
+				OnChecked: func(item *TorrentItem) {
+					report.Checked(item.Handle.InfoHash().String(), item.Downloaded, item.Size)
+				},
+			}
+			if _, err := addTorrentFile(path, opts); err != nil {
+				result.Err = err
+			}
+			return result
+		}
+
+		// Give up on the rest once too many fail in a row
+		streak := newErrorStreak(appConfig.BatchErrorLimit)
+		for i, path := range paths {
+			result := importFile(path)
+			report.Add(result)
+			if streak.Record(result.Err) {
+				err := streak.Err(i+1, len(paths))
+				log.Printf("Warning: import %v", err)
+				report.Stop(err)
+				break
+			}
+		}
+		log.Printf("Importing %d .torrent file(s) from %s with data in %s", len(paths), torrentDir, dataDir)
+
+		reportDialog := dialog.NewCustom("Import from Another Client", "Close", report.Content, w)
+		reportDialog.Resize(fyne.NewSize(650, 420))
+		reportDialog.Show()
+	}
+
+	// Function to migrate the torrents of another client, reusing their downloaded data
+	showImportDialog := func() {
+		browseFolder := func(entry *widget.Entry) *widget.Button {
+			return widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+				dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+					if err != nil {
+						dialog.ShowError(err, w)
+						return
+					}
+					if uri != nil {
+						entry.SetText(uri.Path())
+					}
+				}, w)
+			})
+		}
+
+		torrentDirInput := widget.NewEntry()
+		torrentDirInput.SetPlaceHolder("Folder of .torrent files, such as the other client's state folder")
+		dataDirInput := widget.NewEntry()
+		dataDirInput.SetPlaceHolder("Folder the other client downloaded into")
+
+		explanation := widget.NewLabel("Each .torrent file is added with its data expected in the data folder under " +
+			"the torrent's name, the way most clients save it. Existing data is checked before anything is " +
+			"downloaded, so complete torrents just seed.")
+		explanation.Wrapping = fyne.TextWrapWord
+
+		var importDialog dialog.Dialog
+		importButton := widget.NewButtonWithIcon("Import", theme.ConfirmIcon(), func() {
+			torrentDir := strings.TrimSpace(torrentDirInput.Text)
+			dataDir := strings.TrimSpace(dataDirInput.Text)
+			for _, dir := range []string{torrentDir, dataDir} {
+				if info, err := os.Stat(dir); dir == "" || err != nil || !info.IsDir() {
+					dialog.ShowError(fmt.Errorf("please choose both folders"), w)
+					return
+				}
+			}
+			importDialog.Hide()
+			showImportReport(torrentDir, dataDir)
+		})
+		importButton.Importance = widget.HighImportance
+
+		content := container.NewVBox(
+			widget.NewForm(
+				widget.NewFormItem("Torrent Files", container.NewBorder(nil, nil, nil, browseFolder(torrentDirInput), torrentDirInput)),
+				widget.NewFormItem("Data", container.NewBorder(nil, nil, nil, browseFolder(dataDirInput), dataDirInput)),
+			),
+			explanation,
+			container.NewHBox(layout.NewSpacer(), importButton),
+		)
+
+		importDialog = dialog.NewCustom("Import from Another Client", "Cancel", content, w)
+		importDialog.Resize(fyne.NewSize(600, 300))
+		importDialog.Show()
+	}
+
+	// Function to create a .torrent file from a file or folder, optionally seeding it
+	showCreateDialog := func() {
+		sourceInput := widget.NewEntry()
+		sourceInput.SetPlaceHolder("File or folder to share")
+		sizeLabel := widget.NewLabel("-")
+		piecesLabel := widget.NewLabel("-")
+		piecesLabel.Wrapping = fyne.TextWrapWord
+
+		// The piece length is suggested from the content size unless the user picks one
+		const autoPieceLength = "Auto"
+		var totalSize int64
+		pieceLengthOptions := []string{autoPieceLength}
+		for _, length := range pieceLengthChoices() {
+			pieceLengthOptions = append(pieceLengthOptions, formatPieceLength(length))
+		}
+		pieceLengthSelect := widget.NewSelect(pieceLengthOptions, nil)
+		chosenPieceLength := func() int64 {
+			for _, length := range pieceLengthChoices() {
+				if formatPieceLength(length) == pieceLengthSelect.Selected {
+					return length
+				}
+			}
+			return suggestPieceLength(totalSize)
+		}
+		updatePieces := func() {
+			if totalSize <= 0 {
+				piecesLabel.SetText("-")
+				return
+			}
+			text := describePieces(totalSize, chosenPieceLength())
+			if pieceLengthSelect.Selected == autoPieceLength {
+				text = "Suggested: " + text
+			}
+			piecesLabel.SetText(text)
+		}
+		pieceLengthSelect.OnChanged = func(string) {
+			updatePieces()
+		}
+		pieceLengthSelect.SetSelected(autoPieceLength)
+
+		// Add up the content in the background, ignoring results for a path since changed
+		sourceInput.OnChanged = func(path string) {
+			path = strings.TrimSpace(path)
+			totalSize = 0
+			sizeLabel.SetText("-")
+			updatePieces()
+			if path == "" {
+				return
+			}
+			go func() {
+				size, err := contentSize(path)
+				fyne.Do(func() {
+					if strings.TrimSpace(sourceInput.Text) != path || err != nil {
+						return
+					}
+					totalSize = size
+					sizeLabel.SetText(HumanReadableSize(size))
+					updatePieces()
+				})
+			}()
+		}
+
+		browseFileButton := widget.NewButtonWithIcon("", theme.FileIcon(), func() {
+			dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				if reader != nil {
+					reader.Close()
+					sourceInput.SetText(reader.URI().Path())
+				}
+			}, w)
+		})
+		browseFolderButton := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+			dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				if uri != nil {
+					sourceInput.SetText(uri.Path())
+				}
+			}, w)
+		})
+
+		trackersInput := widget.NewMultiLineEntry()
+		trackersInput.SetPlaceHolder("Announce URLs, one per line")
+		commentInput := widget.NewEntry()
+		commentInput.SetPlaceHolder("Optional")
+		privateInput := widget.NewCheck("Private torrent (trackers only, no DHT or peer exchange)", nil)
+		seedInput := widget.NewCheck("Start seeding once created", nil)
+		seedInput.SetChecked(true)
+
+		// Helper function to hash the content and write the .torrent file to path
+		create := func(source, path string, pieceLength int64, trackers []string) {
+			progressDialog := dialog.NewCustomWithoutButtons("Creating Torrent",
+				container.NewVBox(widget.NewLabel(fmt.Sprintf("Hashing %s...", source)), widget.NewProgressBarInfinite()), w)
+			progressDialog.Show()
+
+			go func() {
+				mi, err := createTorrent(source, pieceLength, trackers, strings.TrimSpace(commentInput.Text), privateInput.Checked)
+				if err == nil {
+					var f *os.File
+					if f, err = os.Create(path); err == nil {
+						err = mi.Write(f)
+						if closeErr := f.Close(); err == nil {
+							err = closeErr
+						}
+					}
+				}
+
+				fyne.Do(func() {
+					progressDialog.Hide()
+					if err != nil {
+						dialog.ShowError(fmt.Errorf("error creating torrent: %v", err), w)
+						return
+					}
+					log.Printf("Created %s from %s with %s pieces", path, source, formatPieceLength(pieceLength))
+					if !seedInput.Checked {
+						dialog.ShowInformation("Torrent Created", fmt.Sprintf("Saved %s.", path), w)
+						return
+					}
+
+					// The content is where the new torrent expects it, so it seeds straight away
+					opts := AddOptions{
+						SaveDir:   filepath.Dir(source),
+						CrossSeed: true,
+						OnChecked: func(item *TorrentItem) {
+							log.Printf("Seeding created torrent %s", item.Name)
+						},
+					}
+					if _, err := addTorrentFile(path, opts); err != nil {
+						dialog.ShowError(fmt.Errorf("error adding created torrent: %v", err), w)
+					}
+				})
+			}()
+		}
+
+		var createDialog dialog.Dialog
+		createButton := widget.NewButtonWithIcon("Create", theme.ConfirmIcon(), func() {
+			source := strings.TrimSpace(sourceInput.Text)
+			if _, err := os.Stat(source); source == "" || err != nil {
+				dialog.ShowError(fmt.Errorf("please choose the file or folder to share"), w)
+				return
+			}
+			if totalSize <= 0 {
+				dialog.ShowError(fmt.Errorf("there is nothing to share in %s", source), w)
+				return
+			}
+			pieceLength := chosenPieceLength()
+			if err := validatePieceLength(pieceLength); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			trackers, invalid := ParseTrackerURLs(trackersInput.Text)
+			if len(invalid) > 0 {
+				dialog.ShowError(fmt.Errorf("invalid tracker URL(s): %s", strings.Join(invalid, ", ")), w)
+				return
+			}
+
+			saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				if writer == nil {
+					return
+				}
+				path := writer.URI().Path()
+				writer.Close()
+				createDialog.Hide()
+				create(source, path, pieceLength, trackers)
+			}, w)
+			saveDialog.SetFileName(filepath.Base(source) + ".torrent")
+			saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".torrent"}))
+			saveDialog.Show()
+		})
+		createButton.Importance = widget.HighImportance
+
+		content := container.NewVBox(
+			widget.NewForm(
+				widget.NewFormItem("Content", container.NewBorder(nil, nil, nil,
+					container.NewHBox(browseFileButton, browseFolderButton), sourceInput)),
+				widget.NewFormItem("Size", sizeLabel),
+				widget.NewFormItem("Piece Length", pieceLengthSelect),
+				widget.NewFormItem("Pieces", piecesLabel),
+				widget.NewFormItem("Trackers", trackersInput),
+				widget.NewFormItem("Comment", commentInput),
+				widget.NewFormItem("", privateInput),
+				widget.NewFormItem("", seedInput),
+			),
+			container.NewHBox(layout.NewSpacer(), createButton),
+		)
+
+		createDialog = dialog.NewCustom("Create Torrent", "Cancel", content, w)
+		createDialog.Resize(fyne.NewSize(600, 520))
+		createDialog.Show()
+	}
+
+	// Helper function to delete a removed torrent's files, showing progress and allowing
+	// the deletion to be canceled between files
+	deleteTorrentFiles := func(name string, paths []string, root string) {
+		progressBar := widget.NewProgressBar()
+		progressLabel := widget.NewLabel(fmt.Sprintf("Deleting %d file(s) of '%s'...", len(paths), name))
+		cancel := make(chan struct{})
+
+		var progressDialog *dialog.CustomDialog
+		cancelButton := widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), nil)
+		cancelButton.OnTapped = func() {
+			cancelButton.Disable()
+			progressLabel.SetText("Canceling...")
+			close(cancel)
+		}
+		progressDialog = dialog.NewCustomWithoutButtons("Deleting Files",
+			container.NewVBox(progressLabel, progressBar), w)
+		progressDialog.SetButtons([]fyne.CanvasObject{cancelButton})
+		progressDialog.Resize(fyne.NewSize(400, 150))
+		progressDialog.Show()
+
+		go func() {
+			deleted, err := deleteDataFiles(paths, root, cancel, func(done, total int) {
+				fyne.Do(func() {
+					progressBar.SetValue(float64(done) / float64(total))
+				})
+			})
+
+			canceled := false
+			select {
+			case <-cancel:
+				canceled = true
+			default:
+			}
+
+			if canceled {
+				log.Printf("Warning: canceled deleting files of '%s' after %d of %d file(s)", name, len(deleted), len(paths))
+				for _, path := range deleted {
+					log.Printf("Warning: deleted %s before the cancel", path)
+				}
+			}
+
+			fyne.Do(func() {
+				progressDialog.Hide()
+				if err != nil {
+					recordActivity(ActivityError, "", name, fmt.Sprintf("Couldn't delete all files of '%s': %v", name, err))
+					dialog.ShowError(fmt.Errorf("error deleting files: %v", err), w)
+				} else if canceled {
+					dialog.ShowInformation("Deletion Canceled",
+						fmt.Sprintf("Deleted %d of %d file(s) of '%s' before canceling.", len(deleted), len(paths), name), w)
+				}
+			})
+		}()
+	}
+
+	// Function to fill a torrent's missing pieces from another copy of its files, so a
+	// nearly complete torrent with no seeds left can be finished from a local folder
+	repairTorrent = func(item *TorrentItem) {
+		if item.Handle.Info() == nil {
+			dialog.ShowInformation("Repair from Folder", "The torrent's metadata hasn't been received yet.", w)
+			return
+		}
+
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if uri == nil {
+				return
+			}
+			dir := uri.Path()
+
+			progressBar := widget.NewProgressBar()
+			progressLabel := widget.NewLabel(fmt.Sprintf("Checking missing pieces of '%s' against %s...", item.Name, dir))
+			progressLabel.Wrapping = fyne.TextWrapWord
+			cancel := make(chan struct{})
+
+			var progressDialog *dialog.CustomDialog
+			cancelButton := widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), nil)
+			cancelButton.OnTapped = func() {
+				cancelButton.Disable()
+				progressLabel.SetText("Canceling...")
+				close(cancel)
+			}
+			progressDialog = dialog.NewCustomWithoutButtons("Repairing from Folder",
+				container.NewVBox(progressLabel, progressBar), w)
+			progressDialog.SetButtons([]fyne.CanvasObject{cancelButton})
+			progressDialog.Resize(fyne.NewSize(450, 170))
+			progressDialog.Show()
+
+			go func() {
+				result, err := repairFromFolder(item, dir, cancel, func(done, total int) {
+					fyne.Do(func() {
+						progressBar.SetValue(float64(done) / float64(total))
+					})
+				})
+				log.Printf("Repaired '%s' from %s: filled %d of %d missing piece(s), %d didn't match",
+					item.Name, dir, result.Filled, result.Missing, result.Mismatch)
+
+				fyne.Do(func() {
+					progressDialog.Hide()
+					if err != nil {
+						dialog.ShowError(fmt.Errorf("error repairing from folder: %v", err), w)
+						return
+					}
+					message := fmt.Sprintf("Filled %d of %d missing piece(s) of '%s' from %s.",
+						result.Filled, result.Missing, item.Name, dir)
+					if result.Mismatch > 0 {
+						message += fmt.Sprintf(" %d piece(s) in the folder didn't match the torrent.", result.Mismatch)
+					}
+					dialog.ShowInformation("Repair from Folder", message, w)
+					updateDetailsPanel()
+				})
+			}()
+		}, w)
+	}
+
+	// Offers to undo the last removal for a few seconds
+	undoBar := newSnackbar()
+
+	// Offers to add a torrent link found in the clipboard when the window gains focus.
+	// Links already offered are remembered so a dismissed one doesn't come back.
+	clipboardBar := newSnackbar()
+	offeredLinks := make(map[string]bool)
+	checkClipboard := func() {
+		if !appConfig.WatchClipboard {
+			return
+		}
+		link, name := copiedTorrent(a.Clipboard().Content())
+		if link == "" || offeredLinks[link] {
+			return
+		}
+		offeredLinks[link] = true
+
+		// Skip magnets for torrents that are already in the list
+		if m, err := metainfo.ParseMagnetUri(link); err == nil {
+			if _, ok := torrentList[m.InfoHash.String()]; ok {
+				return
+			}
+		}
+
+		clipboardBar.Show(fmt.Sprintf("Add copied torrent '%s'?", name), "Add", func() {
+			opts := defaultAddOptions()
+			if IsTorrentURL(link) {
+				addTorrentURL(link, opts)
+			} else if _, err := addMagnet(link, opts); err != nil {
+				dialog.ShowError(fmt.Errorf("error adding torrent: %v", err), w)
+			}
+		}, nil, 15*time.Second)
+	}
+	a.Lifecycle().SetOnEnteredForeground(checkClipboard)
+
+	// Helper function to remove a torrent, optionally deleting its data. Removals that keep
+	// the data can be undone from the snackbar until it expires.
+	removeTorrent := func(item *TorrentItem, deleteFiles bool) {
+		// Find the torrent's hash in the list
+		var hash string
+		for h, t := range torrentList {
+			if t == item {
+				hash = h
+				break
+			}
+		}
+		if hash == "" {
+			return
+		}
+
+		// Work out where the data is before the torrent is dropped
+		var dataPaths []string
+		kept := 0
+		if deleteFiles && item.Handle != nil && item.SavePath != "" {
+			allPaths := dataFilePaths(item)
+			dataPaths = exclusiveDataPaths(allPaths, item, hash, torrentList, savePaths.Overlapping(item.SavePath, hash))
+			kept = len(allPaths) - len(dataPaths)
+		}
+
+		// Remember the torrent's state in case the removal is undone
+		saved := newSessionTorrent(hash, item)
+
+		// Drop the torrent
+		if item.Handle != nil {
+			item.Handle.Drop()
+		}
+
+		// Remove from our list and the saved session, freeing the save path
+		delete(torrentList, hash)
+		savePaths.Release(hash)
+		saveSession()
+
+		// Update the UI, which also clears the selection if this torrent was selected
+		refreshLibrary()
+
+		// Update the details panel to show "No torrent selected"
+		updateDetailsPanel()
+
+		// Validate torrent list
+		validateTorrents()
+
+		// Helper function to remove the metainfo once the removal can no longer be undone
+		removeTorrentFile := func() {
+			// Keep it if the torrent was added again in the meantime
+			if _, ok := torrentList[hash]; ok {
+				return
+			}
+			if err := RemoveTorrentFile(hash); err != nil {
+				log.Printf("Error removing torrent file: %v", err)
+			}
+		}
+
+		// Say which files were left for the other torrents that use them
+		if kept > 0 {
+			recordActivity(ActivityError, hash, item.Name,
+				fmt.Sprintf("Kept %d file(s) of '%s' that other torrents also use", kept, item.Name))
+		}
+
+		// Delete the data now that the torrent no longer holds its files open. There is
+		// nothing left to undo afterwards.
+		if len(dataPaths) > 0 {
+			removeTorrentFile()
+			deleteTorrentFiles(item.Name, dataPaths, item.SavePath)
+			return
+		}
+
+		if kept > 0 {
+			dialog.ShowInformation("Files Kept", fmt.Sprintf("None of the files of '%s' were deleted, "+
+				"as other torrents use all %d of them. The torrent was removed.", item.Name, kept), w)
+		}
+		undoBar.Show(fmt.Sprintf("Removed '%s'", item.Name), "Undo", func() {
+			// Bring the torrent back as it was; the startup policy only applies at launch
+			if err := restoreTorrent(saved, saved.Paused); err != nil {
+				dialog.ShowError(fmt.Errorf("error restoring torrent: %v", err), w)
+			}
+		}, removeTorrentFile, 10*time.Second)
+	}
+
+	// Function to remove a torrent after confirmation, with the option to delete its data too
+	confirmRemoveTorrent = func(item *TorrentItem) {
+		deleteFilesCheck := widget.NewCheck("Also delete downloaded files", nil)
+		confirmDialog := dialog.NewCustomConfirm(
+			"Remove Torrent",
+			"Remove",
+			"Cancel",
+			container.NewVBox(
+				widget.NewLabel(fmt.Sprintf("Are you sure you want to remove '%s'?", item.Name)),
+				deleteFilesCheck,
+			),
+			func(confirmed bool) {
+				if confirmed {
+					removeTorrent(item, deleteFilesCheck.Checked)
+				}
+			}, w)
+		confirmDialog.Show()
+	}
+
+	// Function to remove a torrent straight away. It never deletes data, so the snackbar
+	// can always undo it.
+	quickRemoveTorrent = func(item *TorrentItem) {
+		removeTorrent(item, false)
+	}
+
+	// Helper function to remove the selected torrent from the toolbar or the Delete key
+	removeSelectedTorrent := func() {
+		if selectedHash == "" {
+			dialog.ShowInformation("Info", "Please select a torrent to remove", w)
+			return
+		}
+
+		// Get the selected torrent
+		selectedTorrent, ok := torrentList[selectedHash]
+		if !ok {
+			dialog.ShowError(fmt.Errorf("the selected torrent no longer exists"), w)
+			refreshLibrary()
+			return
+		}
+
+		// Validate torrent
+		if selectedTorrent == nil {
+			dialog.ShowError(fmt.Errorf("selected torrent is invalid"), w)
+			return
+		}
+
+		// Validate handle
+		if selectedTorrent.Handle == nil {
+			dialog.ShowError(fmt.Errorf("torrent handle is invalid"), w)
+			// Clean up the invalid torrent
+			delete(torrentList, selectedHash)
+			refreshLibrary()
+			return
+		}
+
+		if appConfig.ConfirmRemove {
+			confirmRemoveTorrent(selectedTorrent)
+		} else {
+			quickRemoveTorrent(selectedTorrent)
+		}
+	}
+
+	// Helper function to choose a .torrent file to add
+	showOpenFileDialog := func() {
+		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if reader == nil {
+				return
+			}
+
+			// Read the torrent file
+			defer reader.Close()
+
+			// Get the file path from the URI
+			filePath := reader.URI().Path()
+
+			// Add the torrent
+			if _, err := addTorrentFile(filePath, defaultAddOptions()); err != nil {
+				dialog.ShowError(fmt.Errorf("error adding torrent: %v", err), w)
+				return
+			}
+		}, w)
+		fd.SetFilter(storage.NewExtensionFileFilter([]string{".torrent"}))
+		fd.Show()
+	}
+
+	// Helper function to show the settings dialog, applying what can change while running
+	openSettings := func() {
+		showSettingsDialog(w, appConfig, cfg.DataDir, func() {
+			applySpeedLimits()
+			applyStatusBarVisibility()
+			applyColumnWidths()
+		})
+	}
+
+	// Helper function to show the About dialog, which also lists the keyboard shortcuts
+	showAbout := func() {
+		text := "Reed Torrent Client v1.0.0\n\nA lightweight torrent client built with Go using the anacrolix/torrent library and Fyne for the UI."
+		if appConfig.CommandPalette {
+			text += "\n\nPress Ctrl+K (Cmd+K on macOS) to open the command palette."
+		}
+		dialog.ShowInformation("About Reed Torrent Client", text, w)
+	}
+
+	// Create a toolbar with action buttons
+	toolbar := widget.NewToolbar(
+		widget.NewToolbarAction(theme.ContentAddIcon(), func() {
+			showAddDialog()
+		}),
+		widget.NewToolbarAction(theme.FolderOpenIcon(), func() {
+			showOpenFileDialog()
+		}),
+		widget.NewToolbarAction(theme.ContentCopyIcon(), func() {
+			showCrossSeedDialog()
+		}),
+		widget.NewToolbarAction(theme.StorageIcon(), func() {
+			showImportDialog()
+		}),
+		widget.NewToolbarAction(theme.DocumentCreateIcon(), func() {
+			showCreateDialog()
+		}),
+		widget.NewToolbarSeparator(),
+		widget.NewToolbarAction(theme.DeleteIcon(), func() {
+			removeSelectedTorrent()
+		}),
+		widget.NewToolbarSpacer(),
+		widget.NewToolbarAction(theme.SettingsIcon(), func() {
+			openSettings()
+		}),
+		widget.NewToolbarAction(theme.HelpIcon(), func() {
+			showAbout()
+		}),
+	)
+
+	// The status bar is already declared above so we don't need to redeclare it here
+
+	// Torrent currently shown in the details panel
+	var detailsTorrent *TorrentItem
+
+	// Columns of the Files tab, resized by dragging the dividers of their header
+	filesColumns := newListColumns(columnWidthsOr(appConfig.FileColumnWidths, defaultFileColumnWidths), 0)
+
+	// Files tab listing the files of the shown torrent with their selection and priority
+	var filesList *widget.List
+	filesList = widget.NewList(
+		func() int {
+			// Double-check that the files are still available (could change between renders)
+			if detailsTorrent != nil {
+				return len(detailsTorrent.Files)
+			}
+			return 0
+		},
+		func() fyne.CanvasObject {
+			return container.NewBorder(
+				nil,
+				nil,
+				container.NewHBox(
+					widget.NewCheck("", nil),
+					widget.NewIcon(theme.FileIcon()),
+				),
+				container.NewHBox(
+					widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), nil),
+					widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), nil),
+					widget.NewButtonWithIcon("", theme.FolderOpenIcon(), nil),
+				),
+				filesColumns.Row(
+					container.NewVBox(
+						newTooltipLabel("Filename"),
+						widget.NewProgressBar(),
+					),
+					widget.NewLabel("Size"),
+					widget.NewSelect(FilePriorityNames, nil),
+				),
+			)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			// Safety checks
+			torrentItem := detailsTorrent
+			if torrentItem == nil || torrentItem.Handle == nil || int(id) >= len(torrentItem.Files) {
+				return
+			}
+
+			file := &torrentItem.Files[id]
+			index := int(id)
+
+			row := obj.(*fyne.Container)
+			columns := row.Objects[0].(*fyne.Container)
+			left := row.Objects[1].(*fyne.Container)
+			right := row.Objects[2].(*fyne.Container)
+			name := columns.Objects[0].(*fyne.Container)
+			selectedCheck := left.Objects[0].(*widget.Check)
+			filenameLabel := name.Objects[0].(*tooltipLabel)
+			progressBar := name.Objects[1].(*widget.ProgressBar)
+			sizeLabel := columns.Objects[1].(*widget.Label)
+			prioritySelect := columns.Objects[2].(*widget.Select)
+			renameButton := right.Objects[0].(*widget.Button)
+			recheckButton := right.Objects[1].(*widget.Button)
+			revealButton := right.Objects[2].(*widget.Button)
+
+			// Show the alias if the file has one, with its real path on hover
+			filenameLabel.SetText(file.DisplayName())
+			filenameLabel.Tooltip = ""
+			if file.Alias != "" {
+				filenameLabel.Tooltip = file.Path
+			}
+			if file.Checking {
+				filenameLabel.SetText(file.DisplayName() + " (checking...)")
+				recheckButton.Disable()
+			} else {
+				recheckButton.Enable()
+			}
+			sizeLabel.SetText(HumanReadableSize(file.Size))
+			progressBar.SetValue(file.Progress)
+
+			// Detach the handlers while showing the current values so they don't fire
+			selectedCheck.OnChanged = nil
+			selectedCheck.SetChecked(file.Selected)
+			prioritySelect.OnChanged = nil
+			prioritySelect.SetSelected(file.Priority.String())
+
+			// Metadata-only torrents just remember the choice until they are started
+			selectedCheck.OnChanged = func(checked bool) {
+				file.Selected = checked
+				if !torrentItem.MetadataOnly {
+					applyFileSelections(torrentItem.Handle, torrentItem.Files)
+				}
+				saveSession()
+			}
+			prioritySelect.OnChanged = func(name string) {
+				file.Priority = ParseFilePriority(name)
+				if !torrentItem.MetadataOnly {
+					applyFileSelections(torrentItem.Handle, torrentItem.Files)
+				}
+				saveSession()
+			}
+
+			// Give the file a name of its own in this list; the file on disk keeps its name
+			renameButton.OnTapped = func() {
+				aliasInput := widget.NewEntry()
+				aliasInput.SetPlaceHolder(filepath.Base(file.Path))
+				aliasInput.SetText(file.Alias)
+				form := []*widget.FormItem{
+					widget.NewFormItem("File", widget.NewLabel(file.Path)),
+					widget.NewFormItem("Display Name", aliasInput),
+				}
+				dialog.ShowForm("Rename File", "Save", "Cancel", form, func(ok bool) {
+					if !ok {
+						return
+					}
+					file.Alias = strings.TrimSpace(aliasInput.Text)
+					filesList.RefreshItem(id)
+					saveSession()
+				}, w)
+			}
+
+			// Hash just this file's pieces again, showing its progress once they are checked
+			recheckButton.OnTapped = func() {
+				file.Checking = true
+				filesList.RefreshItem(id)
+				go func() {
+					good, total := recheckFile(torrentItem.Handle, index)
+					f := torrentItem.Handle.Files()[index]
+					log.Printf("Rechecked %s of %s: %d of %d piece(s) good", file.Path, torrentItem.Name, good, total)
+					fyne.Do(func() {
+						file.Checking = false
+						if f.Length() > 0 {
+							file.Progress = float64(f.BytesCompleted()) / float64(f.Length())
+						}
+						updateDetailsPanel()
+						if good < total {
+							dialog.ShowInformation("Recheck Complete", fmt.Sprintf("%d of %d piece(s) of %s are missing or corrupt "+
+								"and will be downloaded again.", total-good, total, file.DisplayName()), w)
+						} else {
+							dialog.ShowInformation("Recheck Complete", fmt.Sprintf("All %d piece(s) of %s are good.",
+								total, file.DisplayName()), w)
+						}
+					})
+				}()
+			}
+
+			// Show this file in the file manager
+			revealButton.OnTapped = func() {
+				if err := revealDataFile(dataFilePath(torrentItem, index)); err != nil {
+					dialog.ShowError(fmt.Errorf("error revealing file: %v", err), w)
+				}
+			}
+		},
+	)
+
+	// The header lines up with the rows' columns by leaving room for their check, icon and buttons
+	filesHeader := container.NewBorder(nil, nil,
+		columnSpacer(container.NewHBox(widget.NewCheck("", nil), widget.NewIcon(theme.FileIcon()))),
+		columnSpacer(container.NewHBox(
+			widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), nil),
+			widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), nil),
+			widget.NewButtonWithIcon("", theme.FolderOpenIcon(), nil),
+		)),
+		filesColumns.Header("Name", "Size", "Priority"))
+
+	// Column widths are saved as soon as a divider is let go
+	filesColumns.OnResized = func() {
+		appConfig.FileColumnWidths = append([]float32(nil), filesColumns.Widths...)
+		if err := appConfig.Save(); err != nil {
+			log.Printf("Error saving settings: %v", err)
+		}
+	}
+
+	// General tab, rebuilt on every update
+	generalContainer := container.NewVBox()
+
+	// Speed graph in the General tab, kept between updates so its history builds up
+	torrentGraph := newTorrentSpeedGraph()
+
+	// Trackers tab, only reset when a different torrent is shown so edits survive updates
+	trackersEditor := newTrackerEditor()
+	trackersEditor.OnSaved = func() {
+		saveSession()
+	}
+
+	// Peers tab, updated with a fresh snapshot on every update
+	peersTab := newPeersView(columnWidthsOr(appConfig.PeerColumnWidths, defaultPeerColumnWidths))
+	peersTab.Columns.OnResized = func() {
+		appConfig.PeerColumnWidths = append([]float32(nil), peersTab.Columns.Widths...)
+		if err := appConfig.Save(); err != nil {
+			log.Printf("Error saving settings: %v", err)
+		}
+	}
+	peersTab.OnSeedsChanged = func(item *TorrentItem) {
+		saveSession()
+	}
+	peersTab.OnSlotsChanged = func(item *TorrentItem) {
+		applyConnLimit(item)
+		saveSession()
+	}
+
+	// Settings reset the widths by clearing the saved ones
+	applyColumnWidths = func() {
+		filesColumns.SetWidths(columnWidthsOr(appConfig.FileColumnWidths, defaultFileColumnWidths))
+		peersTab.Columns.SetWidths(columnWidthsOr(appConfig.PeerColumnWidths, defaultPeerColumnWidths))
+	}
+
+	// Notes tab, saved with the session
+	notesTab := newNotesView()
+	notesTab.OnChanged = func(item *TorrentItem) {
+		saveSession()
+		refreshLibrary()
+	}
+
+	// What kinds of content the torrent holds, above its file list
+	contentLabel := widget.NewLabel("")
+	contentLabel.Wrapping = fyne.TextWrapWord
+
+	trackersTabItem := container.NewTabItemWithIcon("Trackers", theme.StorageIcon(), trackersEditor.Content)
+	detailsTabs := container.NewAppTabs(
+		container.NewTabItemWithIcon("General", theme.InfoIcon(), container.NewVScroll(generalContainer)),
+		container.NewTabItemWithIcon("Files", theme.FileIcon(), container.NewBorder(
+			container.NewVBox(contentLabel, widget.NewSeparator(), filesHeader), nil, nil, nil, filesList)),
+		container.NewTabItemWithIcon("Peers", theme.AccountIcon(), peersTab.Content),
+		trackersTabItem,
+		container.NewTabItemWithIcon("Notes", theme.DocumentIcon(), notesTab.Content),
+	)
+
+	// When the tracker states were last read. Reading them means writing out the whole
+	// client status, so it is only done every few seconds while the Trackers tab is showing.
+	var trackerStatusTime time.Time
+
+	// Create a detail panel for the selected torrent, showing a message until one is selected
+	noSelectionLabel := widget.NewLabel("No torrent selected")
+	noSelectionLabel.Importance = widget.LowImportance
+	detailsContainer := container.NewStack(noSelectionLabel, detailsTabs)
+	detailsTabs.Hide()
+
+	// Function to update the details panel
+	updateDetailsPanel = func() {
+		// Helper to show a message in place of the details
+		showMessage := func(message string) {
+			noSelectionLabel.SetText(message)
+			noSelectionLabel.Show()
+			detailsTabs.Hide()
+			detailsTorrent = nil
+			filesList.Refresh()
+			trackersEditor.SetTorrent(nil)
+			peersTab.SetTorrent(nil)
+			notesTab.SetTorrent(nil)
+			torrentGraph.SetTorrent(nil)
+		}
+
+		if selectedHash == "" {
+			showMessage("No torrent selected")
+			return
+		}
+
+		// The selected torrent may have been removed in the background since it was selected
+		selectedTorrent := torrentList[selectedHash]
+		if selectedTorrent == nil {
+			selectedHash = ""
+			selectedRow = -1
+			list.UnselectAll()
+			showMessage("No torrent selected")
+			return
 		}
 
 		// Additional safety check
 		if selectedTorrent.Handle == nil || selectedTorrent.Handle.Info() == nil {
-			detailsContainer.Add(widget.NewLabel("Torrent information not available yet"))
-			detailsContainer.Refresh()
+			showMessage("Torrent information not available yet")
 			return
 		}
 
+		noSelectionLabel.Hide()
+		detailsTabs.Show()
+
+		// Start the file list from the top when a different torrent is shown
+		if detailsTorrent != selectedTorrent {
+			detailsTorrent = selectedTorrent
+			filesList.UnselectAll()
+			filesList.ScrollToTop()
+			trackersEditor.SetTorrent(selectedTorrent)
+			peersTab.SetTorrent(selectedTorrent)
+			notesTab.SetTorrent(selectedTorrent)
+			torrentGraph.SetTorrent(selectedTorrent)
+			trackerStatusTime = time.Time{}
+			contentLabel.SetText(formatContentBreakdown(contentBreakdown(selectedTorrent.Handle.Info())))
+		}
+		filesList.Refresh()
+		peersTab.Update(newPeerInfos(selectedTorrent.Handle), effectiveUploadSlots(selectedTorrent, appConfig))
+
+		// Show the latest tracker states, with their errors
+		if detailsTabs.Selected() == trackersTabItem && time.Since(trackerStatusTime) >= 5*time.Second {
+			var status strings.Builder
+			client.WriteStatus(&status)
+			trackersEditor.UpdateStatus(parseTrackerStatuses(status.String(), selectedTorrent.Handle.InfoHash().HexString()))
+			trackerStatusTime = time.Now()
+		}
+
+		// Clear the general tab
+		generalContainer.Objects = nil
+
 		// Add torrent information to the details panel
-		detailsContainer.Add(widget.NewLabelWithStyle(
+		generalContainer.Add(widget.NewLabelWithStyle(
 			selectedTorrent.Name,
 			fyne.TextAlignLeading,
 			fyne.TextStyle{Bold: true},
 		))
 
+		// Only the selected files are written to disk, so sizes are shown for those as well as
+		// for the whole torrent
+		selected, selectedCount := selectedSize(selectedTorrent.Files)
+		remaining := selectedRemaining(selectedTorrent.Handle, selectedTorrent.Files)
+
 		// Create a more detailed info form
+		statusLabel := widget.NewLabel(selectedTorrent.Status)
+		statusLabel.Importance = statusImportance(selectedTorrent)
 		infoForm := widget.NewForm(
-			widget.NewFormItem("Status", widget.NewLabel(selectedTorrent.Status)),
-			widget.NewFormItem("Size", widget.NewLabel(HumanReadableSize(selectedTorrent.Size))),
-			widget.NewFormItem("Downloaded", widget.NewLabel(HumanReadableSize(selectedTorrent.Downloaded))),
+			widget.NewFormItem("Status", statusLabel),
+			widget.NewFormItem("Total Size", widget.NewLabel(HumanReadableSize(selectedTorrent.Size))),
+			widget.NewFormItem("Selected Size", widget.NewLabel(fmt.Sprintf("%s (%d of %d files)",
+				HumanReadableSize(selected), selectedCount, len(selectedTorrent.Files)))),
+			widget.NewFormItem("Downloaded", widget.NewLabel(fmt.Sprintf("%s of %s selected",
+				HumanReadableSize(selected-remaining), HumanReadableSize(selected)))),
 			widget.NewFormItem("Progress", widget.NewLabel(fmt.Sprintf("%.1f%%", selectedTorrent.Progress*100))),
 			widget.NewFormItem("Download Speed", widget.NewLabel(HumanReadableRate(selectedTorrent.DownloadRate))),
 			widget.NewFormItem("Upload Speed", widget.NewLabel(HumanReadableRate(selectedTorrent.UploadRate))),
+			widget.NewFormItem("Share Ratio", widget.NewLabel(selectedTorrent.Transferred.String())),
 			widget.NewFormItem("Peers", widget.NewLabel(fmt.Sprintf("%d", selectedTorrent.Peers))),
+			widget.NewFormItem("Seeds", widget.NewLabel(fmt.Sprintf("%d", selectedTorrent.Seeds))),
 		)
 
+		// Show where the data lives
+		if selectedTorrent.Category != "" {
+			infoForm.Append("Category", widget.NewLabel(selectedTorrent.Category))
+		}
+		if selectedTorrent.SavePath != "" {
+			infoForm.Append("Save Path", widget.NewLabel(selectedTorrent.SavePath))
+		}
+		if selectedTorrent.Preallocate {
+			infoForm.Append("Disk Space", widget.NewLabel("Preallocated"))
+		} else {
+			infoForm.Append("Disk Space", widget.NewLabel("Sparse"))
+		}
+
 		// Add ETA if downloading
 		if selectedTorrent.Progress < 1.0 && selectedTorrent.DownloadRate > 0 {
 			infoForm.Append("ETA", widget.NewLabel(selectedTorrent.ETA))
@@ -668,102 +2604,271 @@ func main() {
 
 		// Add metadata info
 		infoForm.Append("Added", widget.NewLabel(selectedTorrent.AddedAt.Format("2006-01-02 15:04:05")))
+		if !selectedTorrent.CompletedAt.IsZero() {
+			infoForm.Append("Completed", widget.NewLabel(selectedTorrent.CompletedAt.Format("2006-01-02 15:04:05")))
+		}
 
 		// Calculate and show data transferred since added
 		if selectedTorrent.Downloaded > 0 {
 			infoForm.Append("Data Transferred", widget.NewLabel(HumanReadableSize(selectedTorrent.Downloaded)))
 		}
-		detailsContainer.Add(infoForm)
+		if selectedTorrent.Warning != "" {
+			warningLabel := widget.NewLabel(selectedTorrent.Warning)
+			warningLabel.Wrapping = fyne.TextWrapWord
+			infoForm.Append("Warning", warningLabel)
+		}
+		generalContainer.Add(infoForm)
+
+		// Show whether this torrent is ramping up or stalling
+		torrentGraph.Update()
+		generalContainer.Add(widget.NewLabelWithStyle("Speed, last 2 minutes", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		generalContainer.Add(torrentGraph.Content)
 
 		// Actions for this torrent
+		pauseButton := widget.NewButtonWithIcon("Pause", theme.MediaPauseIcon(), func() {
+			setTorrentPaused(selectedTorrent, true)
+		})
+		if selectedTorrent.Paused {
+			pauseButton = widget.NewButtonWithIcon("Resume", theme.MediaPlayIcon(), func() {
+				setTorrentPaused(selectedTorrent, false)
+			})
+		}
 		actionsContainer := container.NewHBox(
-			widget.NewButton("Pause/Resume", func() {
-				// Toggle pause/resume logic will be added later
-				dialog.ShowInformation("Not Implemented", "Pause/Resume functionality will be added soon.", w)
-			}),
+			pauseButton,
 			widget.NewButton("Open Folder", func() {
-				// Open the download folder for this torrent
-				dialog.ShowInformation("Open Folder", "This will open the folder containing the downloaded files.", w)
-				// The actual implementation would be platform-specific
+				// Open the folder containing the downloaded files
+				if err := openDataFolder(selectedTorrent); err != nil {
+					dialog.ShowError(fmt.Errorf("error opening folder: %v", err), w)
+				}
+			}),
+			widget.NewButton("Repair from Folder...", func() {
+				repairTorrent(selectedTorrent)
 			}),
 		)
-		detailsContainer.Add(actionsContainer)
+		// Metadata-only torrents wait for the user to pick files and start them
+		if selectedTorrent.MetadataOnly {
+			start := func() {
+				selectedTorrent.MetadataOnly = false
+				files := slices.Clone(selectedTorrent.Files)
+				go func() {
+					preallocateTorrent(selectedTorrent)
+					applyFileSelections(selectedTorrent.Handle, files)
+				}()
+				saveSession()
+				refreshLibrary()
+				updateDetailsPanel()
+			}
+			startButton := widget.NewButtonWithIcon("Start Download", theme.DownloadIcon(), func() {
+				// Check the chosen files fit before writing any of them
+				needed := selectedRemaining(selectedTorrent.Handle, selectedTorrent.Files)
+				if err := checkDiskSpace(selectedTorrent.SavePath, needed); err != nil {
+					dialog.ShowConfirm("Low Disk Space", fmt.Sprintf("%v\n\nStart the download anyway?", err), func(ok bool) {
+						if ok {
+							start()
+						}
+					}, w)
+					return
+				}
+				start()
+			})
+			startButton.Importance = widget.HighImportance
+			actionsContainer.Objects = append([]fyne.CanvasObject{startButton}, actionsContainer.Objects...)
+		}
+		generalContainer.Add(actionsContainer)
 
-		// If the torrent has files, show them
-		// Use multiple safety checks to prevent nil pointer dereferences
-		if selectedTorrent != nil && selectedTorrent.Handle != nil &&
-			selectedTorrent.Handle.Info() != nil &&
-			len(selectedTorrent.Handle.Info().Files) > 0 {
+		generalContainer.Refresh()
+	}
 
-			detailsContainer.Add(widget.NewLabelWithStyle("Files:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	// Set up list selection to update the details panel - this overrides the previous OnSelected
+	list.OnSelected = func(id widget.ListItemID) {
+		if hashes := libraryHashes(); int(id) < len(hashes) {
+			selectedHash = hashes[id]
+			selectedRow = int(id)
+		}
+		updateDetailsPanel()
+	}
 
-			filesList := widget.NewList(
-				func() int {
-					// Double-check that info is still available (could change between renders)
-					if selectedTorrent != nil && selectedTorrent.Handle != nil &&
-						selectedTorrent.Handle.Info() != nil {
-						return len(selectedTorrent.Handle.Info().Files)
-					}
-					return 0
-				},
-				func() fyne.CanvasObject {
-					return container.NewHBox(
-						widget.NewIcon(theme.FileIcon()),
-						widget.NewLabel("Filename"),
-						widget.NewProgressBar(),
-						widget.NewLabel("Size"),
-					)
-				},
-				func(id widget.ListItemID, obj fyne.CanvasObject) {
-					// Safety checks
-					if selectedTorrent == nil || selectedTorrent.Handle == nil ||
-						selectedTorrent.Handle.Info() == nil ||
-						int(id) >= len(selectedTorrent.Handle.Info().Files) {
-						return
-					}
+	// Filtering changes which rows the list shows, so the selection is looked up again
+	libraryFilter.OnChanged = func() {
+		if appConfig.LibraryFilter != libraryFilter.Filter {
+			appConfig.LibraryFilter = libraryFilter.Filter
+			if err := appConfig.Save(); err != nil {
+				log.Printf("Error saving settings: %v", err)
+			}
+		}
+		refreshLibrary()
+		updateDetailsPanel()
+	}
 
-					file := selectedTorrent.Handle.Info().Files[id]
+	// Create a split container with the list on the left and details on the right
+	libraryContent := container.NewBorder(
+		container.NewVBox(container.NewHBox(libraryHeader, ratioBadge), libraryFilter.Content, widget.NewSeparator()),
+		nil,
+		nil,
+		nil,
+		container.NewStack(list, emptyState),
+	)
+	refreshLibrary()
 
-					hbox := obj.(*fyne.Container)
-					filenameLabel := hbox.Objects[1].(*widget.Label)
-					progressBar := hbox.Objects[2].(*widget.ProgressBar)
-					sizeLabel := hbox.Objects[3].(*widget.Label)
+	// Tabs for the main area of the window
+	// Session-wide transfer statistics
+	statisticsView := newStatisticsView()
 
-					// Get the filename from the path - file.Path is a slice of strings
-					if len(file.Path) > 0 {
-						// Use the last component as the filename
-						filenameLabel.SetText(file.Path[len(file.Path)-1])
-					} else {
-						filenameLabel.SetText("Unknown file")
-					}
-					sizeLabel.SetText(HumanReadableSize(file.Length))
-					progressBar.Value = selectedTorrent.Progress
-				},
-			)
+	// Queue tab, ordering the torrents for the active download limit
+	queueTab := newQueueView(torrentList, appConfig)
+	queueTab.OnChanged = func() {
+		if err := appConfig.Save(); err != nil {
+			log.Printf("Error saving settings: %v", err)
+		}
+		refreshLibrary()
+		saveSession()
+	}
+	queueTab.Refresh()
+
+	// Log tab, updated while it is shown
+	logTab := newLogView(logs, appConfig.LogLevel)
+	logTabItem := container.NewTabItemWithIcon("Log", theme.FileTextIcon(), logTab.Content)
+
+	mainTabs := container.NewAppTabs(
+		container.NewTabItemWithIcon("Library", theme.ListIcon(), libraryContent),
+		container.NewTabItemWithIcon("Statistics", theme.ComputerIcon(), container.NewVScroll(statisticsView.Content)),
+		container.NewTabItemWithIcon("Queue", theme.MenuIcon(), queueTab.Content),
+		container.NewTabItemWithIcon("Activity", theme.HistoryIcon(), activity.Content),
+		logTabItem,
+	)
 
-			// Wrap the files list in a scroll container with fixed height
-			filesScroll := container.NewVScroll(filesList)
-			filesScroll.SetMinSize(fyne.NewSize(0, 150))
-			detailsContainer.Add(filesScroll)
-		} else if selectedTorrent.Handle.Info() != nil {
-			// Single file torrent
-			detailsContainer.Add(widget.NewLabelWithStyle("Single File:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
-			detailsContainer.Add(widget.NewLabel(selectedTorrent.Name))
+	// Quick actions from the activity feed
+	activity.OnOpenFolder = func(infoHash string) {
+		if item, ok := torrentList[infoHash]; ok {
+			if err := openDataFolder(item); err != nil {
+				dialog.ShowError(fmt.Errorf("error opening folder: %v", err), w)
+			}
+		}
+	}
+	// Helper function to show a torrent in the library and select it
+	selectTorrent = func(infoHash string) {
+		// Show every torrent if the filter hides this one
+		if _, ok := torrentList[infoHash]; ok && !slices.Contains(libraryHashes(), infoHash) {
+			libraryFilter.Clear()
 		}
+		for row, hash := range libraryHashes() {
+			if hash == infoHash {
+				mainTabs.SelectIndex(0)
+				list.Select(row)
+				list.ScrollTo(row)
+				return
+			}
+		}
+	}
+	activity.OnSelect = selectTorrent
 
-		detailsContainer.Refresh()
+	// Helper function to pause or resume every torrent that isn't already
+	setAllTorrentsPaused := func(paused bool) {
+		for _, item := range torrentList {
+			if item.Paused != paused {
+				setTorrentPaused(item, paused)
+			}
+		}
 	}
 
-	// Set up list selection to update the details panel - this overrides the previous OnSelected
-	list.OnSelected = func(id widget.ListItemID) {
-		selectedIndex = int(id)
-		updateDetailsPanel()
+	// Helper function to drop the torrents from the last session that couldn't be restored,
+	// along with their metainfo, once the user no longer wants them
+	forgetUnrestored := func() {
+		if len(unrestored) == 0 {
+			dialog.ShowInformation("Forget Unrestored Torrents", "Every torrent from the last session was restored.", w)
+			return
+		}
+		names := make([]string, 0, len(unrestored))
+		for _, saved := range unrestored {
+			names = append(names, saved.Name)
+		}
+		dialog.ShowConfirm("Forget Unrestored Torrents",
+			fmt.Sprintf("Forget %d torrent(s) that couldn't be restored? Their data is kept.\n\n%s",
+				len(unrestored), strings.Join(names, "\n")),
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				for _, saved := range unrestored {
+					if _, listed := torrentList[saved.InfoHash]; listed {
+						continue
+					}
+					if err := RemoveTorrentFile(saved.InfoHash); err != nil {
+						log.Printf("Error removing torrent file: %v", err)
+					}
+				}
+				unrestored = unrestored[:0]
+				saveSession()
+			}, w)
+	}
+
+	// Command palette, routing typed commands and pasted links to the handlers above
+	palette := newCommandPalette(w.Canvas())
+	palette.Commands = []PaletteCommand{
+		{Title: "Add Torrent...", Run: showAddDialog},
+		{Title: "Open Torrent File...", Run: showOpenFileDialog},
+		{Title: "Cross-Seed Existing Data...", Run: showCrossSeedDialog},
+		{Title: "Import from Another Client...", Run: showImportDialog},
+		{Title: "Create Torrent...", Run: showCreateDialog},
+		{Title: "Remove Selected Torrent", Run: removeSelectedTorrent},
+		{Title: "Pause All", Run: func() { setAllTorrentsPaused(true) }},
+		{Title: "Resume All", Run: func() { setAllTorrentsPaused(false) }},
+		{Title: "Forget Unrestored Torrents...", Run: forgetUnrestored},
+		{Title: "Copy Magnet Links of Shown Torrents", Run: func() { copyMagnetLinks(libraryHashes()) }},
+		{Title: "Copy All Magnet Links", Run: func() { copyMagnetLinks(sortedInfoHashes(torrentList, appConfig.QueueOrder)) }},
+		{Title: "Open Settings", Run: openSettings},
+		{Title: "Show Storage Locations", Run: func() { showLocationsDialog(w, cfg.DataDir) }},
+		{Title: "About Reed", Run: showAbout},
+	}
+	for i, tab := range mainTabs.Items {
+		index := i
+		palette.Commands = append(palette.Commands, PaletteCommand{
+			Title: "Go to " + tab.Text,
+			Run: func() {
+				mainTabs.SelectIndex(index)
+			},
+		})
+	}
+	palette.Suggest = func(text string) []PaletteCommand {
+		add := func(link string) {
+			if _, err := addMagnet(link, defaultAddOptions()); err != nil {
+				dialog.ShowError(fmt.Errorf("error adding torrent: %v", err), w)
+			}
+		}
+
+		suggestions := make([]PaletteCommand, 0)
+		if ValidateMagnetLink(text) == nil {
+			suggestions = append(suggestions, PaletteCommand{Title: "Add magnet link", Run: func() { add(text) }})
+			suggestions = append(suggestions, PaletteCommand{Title: "Test magnet link", Run: func() { testMagnetLink(text) }})
+		} else if IsTorrentURL(text) {
+			suggestions = append(suggestions, PaletteCommand{
+				Title: "Add torrent from " + text,
+				Run:   func() { addTorrentURL(text, defaultAddOptions()) },
+			})
+		} else if h, err := ParseInfoHash(text); err == nil {
+			suggestions = append(suggestions, PaletteCommand{
+				Title: "Add info-hash " + h.HexString(),
+				Run:   func() { add(MagnetFromInfoHash(h)) },
+			})
+		}
+
+		// Search the library by name and note
+		for _, hash := range sortedInfoHashes(torrentList, appConfig.QueueOrder) {
+			item := torrentList[hash]
+			if item != nil && matchesTorrent(item, text) {
+				infoHash := hash
+				suggestions = append(suggestions, PaletteCommand{
+					Title: "Show " + item.Name,
+					Run:   func() { selectTorrent(infoHash) },
+				})
+			}
+		}
+		return suggestions
 	}
 
-	// Create a split container with the list on the left and details on the right
 	splitContainer := container.NewHSplit(
-		container.NewVBox(list),
-		container.NewScroll(detailsContainer),
+		mainTabs,
+		detailsContainer,
 	)
 	splitContainer.Offset = 0.7 // 70% of space for the list, 30% for details
 
@@ -774,6 +2879,8 @@ func main() {
 			widget.NewSeparator(),
 		),
 		container.NewVBox(
+			undoBar.Content,
+			clipboardBar.Content,
 			widget.NewSeparator(),
 			statusBar,
 		),
@@ -785,129 +2892,363 @@ func main() {
 	// Set the window content
 	w.SetContent(content)
 
+	// Ctrl+K, or Cmd+K on macOS, opens the command palette
+	w.Canvas().AddShortcut(&desktop.CustomShortcut{KeyName: fyne.KeyK, Modifier: fyne.KeyModifierShortcutDefault},
+		func(fyne.Shortcut) {
+			if appConfig.CommandPalette {
+				palette.Show()
+			}
+		})
+
+	// The Delete key removes the selected torrent when nothing else has focus
+	w.Canvas().SetOnTypedKey(func(ev *fyne.KeyEvent) {
+		if ev.Name == fyne.KeyDelete {
+			removeSelectedTorrent()
+		}
+	})
+
+	// Re-add the torrents from the previous session
+	session, err := LoadSession()
+	if err != nil {
+		log.Printf("Error loading session: %v", err)
+		if path, pathErr := SessionPath(); pathErr == nil {
+			if _, statErr := os.Stat(path); statErr == nil {
+				keepSessionFile = true
+			}
+		}
+		message := fmt.Sprintf("The torrent list from the last session couldn't be loaded: %v", err)
+		if keepSessionFile {
+			message += "\n\nChanges to the torrent list won't be saved this session, so the file isn't overwritten."
+		}
+		dialog.ShowInformation("Torrents Not Restored", message, w)
+	}
+	transferredBefore = session.Transferred
+	for _, saved := range session.Torrents {
+		if err := restoreTorrent(saved, appConfig.StartupPolicy.pausedOnLaunch(saved.Paused)); err != nil {
+			log.Printf("Error restoring torrent %s: %v", saved.Name, err)
+			recordActivity(ActivityError, saved.InfoHash, saved.Name, fmt.Sprintf("Couldn't restore '%s': %v", saved.Name, err))
+			unrestored = append(unrestored, saved)
+		}
+	}
+
+	// Fetch the metadata again for torrents that were still waiting for it, keeping those
+	// that can't be added so they aren't lost
+	for _, pending := range session.Pending {
+		spec, opts, err := pending.Spec()
+		if err == nil {
+			opts.Paused = appConfig.StartupPolicy.pausedOnLaunch(opts.Paused)
+			_, err = addTorrentSpec(spec, opts)
+		}
+		if err != nil {
+			log.Printf("Error re-adding torrent %s: %v", pending.InfoHash, err)
+			recordActivity(ActivityError, pending.InfoHash, pending.Name, fmt.Sprintf("Couldn't re-add '%s': %v", pending.Name, err))
+			pendingTorrents[pending.InfoHash] = pending
+		}
+	}
+
+	// Helper function to add the magnet links and .torrent files given on the command line
+	openArgs := func(args []string) {
+		for _, arg := range args {
+			// Skip flags such as the process serial number macOS may pass
+			if strings.HasPrefix(arg, "-") {
+				continue
+			}
+
+			var err error
+			opts := defaultAddOptions()
+			if strings.HasPrefix(strings.ToLower(arg), "magnet:") {
+				_, err = addMagnet(arg, opts)
+			} else if strings.EqualFold(filepath.Ext(arg), ".torrent") {
+				_, err = addTorrentFile(arg, opts)
+			} else {
+				err = fmt.Errorf("not a magnet link or .torrent file: %s", arg)
+			}
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("error adding torrent: %v", err), w)
+			}
+		}
+	}
+	openArgs(os.Args[1:])
+
+	// Accept links from later launches, such as clicking a magnet link in the browser
+	instanceListener, err := listenForInstances(func(args []string) {
+		fyne.Do(func() {
+			w.RequestFocus()
+			openArgs(args)
+		})
+	})
+	if err != nil {
+		log.Printf("Error listening for other instances: %v", err)
+	} else {
+		defer stopListeningForInstances(instanceListener)
+	}
+
+	// Add .torrent files dropped onto the window
+	w.SetOnDropped(func(_ fyne.Position, uris []fyne.URI) {
+		for _, uri := range uris {
+			if !strings.EqualFold(uri.Extension(), ".torrent") {
+				continue
+			}
+			if _, err := addTorrentFile(uri.Path(), defaultAddOptions()); err != nil {
+				dialog.ShowError(fmt.Errorf("error adding torrent: %v", err), w)
+			}
+		}
+	})
+
+	// Watch the bound interface, pausing everything while it is down and resuming once its
+	// address is back
+	if bind.Bound() && appConfig.KillSwitch {
+		go func() {
+			for {
+				available := bind.Available()
+				fyne.Do(func() {
+					if killSwitchTripped == !available {
+						return
+					}
+					killSwitchTripped = !available
+					updateNetworkLabel()
+					if killSwitchTripped {
+						recordActivity(ActivityError, "", bind.Name, fmt.Sprintf("%s went down, paused all torrents", bind.Name))
+					} else {
+						recordActivity(ActivityNetwork, "", bind.Name, fmt.Sprintf("%s is back, resumed all torrents", bind.Name))
+					}
+				})
+				time.Sleep(5 * time.Second)
+			}
+		}()
+	}
+
 	// Start a goroutine to update the UI
 	go func() {
 		// Maps to track previous download/upload byte counts
 		prevDownloaded := make(map[string]int64)
 		prevUploaded := make(map[string]int64)
 
+		// When the battery was last read. Reading it can mean running a command, so it is
+		// only done every half a minute.
+		var batteryTime time.Time
+
 		for {
-			// First validate all torrents to remove any invalid ones
-			validateTorrents()
+			// Read the battery here rather than on the UI goroutine, as it can mean running a command
+			var batteryStatus *BatteryStatus
+			if appConfig.PauseOnBattery && batterySupported && time.Since(batteryTime) >= 30*time.Second {
+				batteryTime = time.Now()
+				if status, err := readBattery(); err == nil {
+					batteryStatus = &status
+				} else if err != errNoBattery {
+					log.Printf("Error reading battery: %v", err)
+				}
+			}
 
 			// Map to track newly completed torrents for notifications
 			newlyCompleted := make(map[string]bool)
 
-			// Update torrent data (non-UI updates)
-			for hash, item := range torrentList {
-				// Skip invalid torrents
-				if item == nil || item.Handle == nil {
-					continue
-				}
+			// Torrents done seeding, to remove once the UI is updated
+			autoRemoved := make([]*TorrentItem, 0)
+
+			// Whether a completion time was recorded that the session should keep
+			sessionChanged := false
+
+			// Whether everything was paused for the battery before this update
+			wasBatteryPaused := false
+
+			// Update the torrents on the UI goroutine, which owns the list and its items
+			fyne.DoAndWait(func() {
+				// First validate all torrents to remove any invalid ones
+				validateTorrents()
 
-				// Skip torrents without info yet
-				if item.Handle.Info() == nil {
-					continue
+				// Pause everything while running low on battery, and resume when plugged in
+				wasBatteryPaused = batteryPaused
+				if !appConfig.PauseOnBattery || !batterySupported {
+					batteryPaused = false
+				} else if batteryStatus != nil {
+					battery = *batteryStatus
+					batteryPaused = pauseForBattery(battery, appConfig.BatteryThreshold)
 				}
 
-				// Get current timestamp
-				now := time.Now()
+				// Hold back downloads beyond the active download limit
+				queued := queuedTorrents(torrentList, appConfig.QueueOrder, appConfig.MaxActiveDownloads)
+
+				// Share the global connection limit between the torrents that aren't queued
+				connShare = connectionShare(appConfig.MaxConnections, len(torrentList)-len(queued))
+
+				// Update torrent data (non-UI updates)
+				for hash, item := range torrentList {
+					// Skip invalid torrents
+					if item == nil || item.Handle == nil {
+						continue
+					}
 
-				// Whether this was previously marked as completed
-				wasCompleted := item.Status == "Completed"
+					// Skip torrents without info yet
+					if item.Handle.Info() == nil {
+						continue
+					}
 
-				// Update downloaded bytes
-				currentBytes := item.Handle.BytesCompleted()
-				previousBytes := item.Downloaded // Store for notification check
-				item.Downloaded = currentBytes
+					// Get current timestamp
+					now := time.Now()
 
-				// Calculate download rate safely
-				if prev, ok := prevDownloaded[hash]; ok {
-					// Calculate time difference since last update
-					timeDiffSec := now.Sub(item.LastUpdate).Seconds()
-					if timeDiffSec > 0 {
-						// Calculate and update download rate (bytes/second)
-						byteDiff := currentBytes - prev
-						if byteDiff >= 0 { // Ensure non-negative
-							item.DownloadRate = int64(float64(byteDiff) / timeDiffSec)
+					// Whether this was previously marked as completed
+					wasCompleted := item.Status == "Completed"
+
+					// Update downloaded bytes
+					currentBytes := item.Handle.BytesCompleted()
+					previousBytes := item.Downloaded // Store for notification check
+					item.Downloaded = currentBytes
+
+					// Calculate download rate safely
+					if prev, ok := prevDownloaded[hash]; ok {
+						// Calculate time difference since last update
+						timeDiffSec := now.Sub(item.LastUpdate).Seconds()
+						if timeDiffSec > 0 {
+							// Calculate and update download rate (bytes/second)
+							byteDiff := currentBytes - prev
+							if byteDiff >= 0 { // Ensure non-negative
+								item.DownloadRate = int64(float64(byteDiff) / timeDiffSec)
+							}
 						}
 					}
-				}
-				// Store current bytes for next rate calculation
-				prevDownloaded[hash] = currentBytes
-
-				// Calculate upload rate (simplified version)
-				// Note: In a real app, we'd track actual bytes uploaded
-				currentUploaded := item.Handle.BytesCompleted()
-				if prev, ok := prevUploaded[hash]; ok && prev > 0 {
-					// Use different variable to avoid shadowing
-					uploadTimeDiff := now.Sub(item.LastUpdate).Seconds()
-					if uploadTimeDiff > 0 {
-						// Calculate rate safely
-						byteDiff := currentUploaded - prev
-						if byteDiff >= 0 { // Ensure non-negative
-							item.UploadRate = int64(float64(byteDiff) / uploadTimeDiff)
+					// Store current bytes for next rate calculation
+					prevDownloaded[hash] = currentBytes
+
+					// Add this launch's data to the earlier launches' for the share ratio
+					item.Transferred = item.transferredBefore.Add(newTransferTotals(item.Handle.Stats().ConnStats))
+
+					// Calculate the upload rate from the torrent data sent to peers
+					currentUploaded := item.Transferred.Uploaded
+					if prev, ok := prevUploaded[hash]; ok {
+						// Use different variable to avoid shadowing
+						uploadTimeDiff := now.Sub(item.LastUpdate).Seconds()
+						if uploadTimeDiff > 0 {
+							// Calculate rate safely
+							byteDiff := currentUploaded - prev
+							if byteDiff >= 0 { // Ensure non-negative
+								item.UploadRate = int64(float64(byteDiff) / uploadTimeDiff)
+							}
 						}
 					}
-				}
-				// Store current upload bytes for next calculation
-				prevUploaded[hash] = currentUploaded
+					// Store current upload bytes for next calculation
+					prevUploaded[hash] = currentUploaded
 
-				// Update progress percentage
-				if item.Size > 0 {
-					item.Progress = float64(item.Downloaded) / float64(item.Size)
-					// Cap progress at 100%
-					if item.Progress > 1.0 {
-						item.Progress = 1.0
+					// Record the speeds of the torrent shown in the details panel
+					if item.downloadHistory != nil {
+						item.downloadHistory.Add(item.DownloadRate)
+						item.uploadHistory.Add(item.UploadRate)
 					}
-				}
 
-				// Update status based on download progress
-				if item.Progress >= 1.0 {
-					item.Status = "Completed"
-					item.ETA = ""
+					// Update progress percentage
+					if item.Size > 0 {
+						item.Progress = float64(item.Downloaded) / float64(item.Size)
+						// Cap progress at 100%
+						if item.Progress > 1.0 {
+							item.Progress = 1.0
+						}
+					}
 
-					// Check if this torrent was just completed
-					if !wasCompleted && previousBytes < item.Size && currentBytes >= item.Size {
-						newlyCompleted[hash] = true
+					// Notice problems that need the user, such as the data's drive going away
+					item.Err = torrentProblem(item)
+					if item.Err == "" {
+						item.Err = item.AutoPaused
 					}
-				} else if item.Handle.Seeding() {
-					item.Status = "Seeding"
-					item.ETA = ""
-				} else {
-					item.Status = fmt.Sprintf("Downloading (%.1f%%)", item.Progress*100)
-
-					// Calculate ETA if downloading at a reasonable rate
-					if item.DownloadRate > 1024 { // Only if downloading faster than 1 KB/s
-						remainingBytes := item.Size - item.Downloaded
-						secondsRemaining := float64(remainingBytes) / float64(item.DownloadRate)
-
-						// Format ETA based on time remaining
-						if secondsRemaining < 60 {
-							item.ETA = fmt.Sprintf("%.0f sec", secondsRemaining)
-						} else if secondsRemaining < 3600 {
-							item.ETA = fmt.Sprintf("%.1f min", secondsRemaining/60)
-						} else if secondsRemaining < 86400 {
-							item.ETA = fmt.Sprintf("%.1f hours", secondsRemaining/3600)
-						} else {
-							item.ETA = fmt.Sprintf("%.1f days", secondsRemaining/86400)
+
+					// Update status based on download progress
+					if item.Checking {
+						item.Status = fmt.Sprintf("Checking files (%.1f%%)", item.Progress*100)
+						item.ETA = ""
+					} else if item.Err != "" {
+						item.Status = "Error: " + item.Err
+						item.ETA = ""
+					} else if item.MetadataOnly {
+						item.Status = "Metadata only"
+						item.ETA = ""
+					} else if item.Paused {
+						item.Status = fmt.Sprintf("Paused (%.1f%%)", item.Progress*100)
+						item.ETA = ""
+					} else if item.Queued {
+						item.Status = fmt.Sprintf("Queued (%.1f%%)", item.Progress*100)
+						item.ETA = ""
+					} else if item.Progress >= 1.0 {
+						item.Status = "Completed"
+						item.ETA = ""
+
+						// Start the seeding goal from when the data was first complete
+						if item.CompletedAt.IsZero() {
+							item.CompletedAt = now
+							sessionChanged = true
 						}
+
+						// Check if this torrent was just completed
+						if !wasCompleted && previousBytes < item.Size && currentBytes >= item.Size {
+							newlyCompleted[hash] = true
+						}
+					} else if item.Handle.Seeding() {
+						item.Status = "Seeding"
+						item.ETA = ""
 					} else {
-						item.ETA = "Unknown"
+						item.Status = fmt.Sprintf("Downloading (%.1f%%)", item.Progress*100)
+
+						// Calculate ETA if downloading at a reasonable rate
+						if item.DownloadRate > 1024 { // Only if downloading faster than 1 KB/s
+							remainingBytes := item.Size - item.Downloaded
+							secondsRemaining := float64(remainingBytes) / float64(item.DownloadRate)
+
+							// Format ETA based on time remaining
+							if secondsRemaining < 60 {
+								item.ETA = fmt.Sprintf("%.0f sec", secondsRemaining)
+							} else if secondsRemaining < 3600 {
+								item.ETA = fmt.Sprintf("%.1f min", secondsRemaining/60)
+							} else if secondsRemaining < 86400 {
+								item.ETA = fmt.Sprintf("%.1f hours", secondsRemaining/3600)
+							} else {
+								item.ETA = fmt.Sprintf("%.1f days", secondsRemaining/86400)
+							}
+						} else {
+							item.ETA = "Unknown"
+						}
+					}
+
+					// Update per-file progress
+					for i, f := range item.Handle.Files() {
+						if i < len(item.Files) && f.Length() > 0 {
+							item.Files[i].Progress = float64(f.BytesCompleted()) / float64(f.Length())
+						}
+					}
+
+					// Update peer and seed counts
+					stats := item.Handle.Stats()
+					item.Peers = stats.ActivePeers
+					item.Seeds = stats.ConnectedSeeders
+
+					// Follow the queue, and the upload slot limit as the torrent completes
+					item.Queued = queued[hash]
+					applyConnLimit(item)
+
+					// Make room for seeds as the torrent nears completion, and keep to its seed cap
+					preferSeeds := item.PreferSeeds && item.Progress >= preferSeedsProgress && item.Progress < 1.0
+					if (preferSeeds || item.MaxSeeds > 0) && !item.stopped {
+						peers := newPeerInfos(item.Handle)
+						for _, p := range peersToDrop(peers, item.MaxSeeds, preferSeeds, len(peers) >= item.connLimit) {
+							p.conn.Close()
+							item.peersDropped++
+						}
 					}
-				}
 
-				// Update peer count safely
-				item.Peers = len(item.Handle.PeerConns())
+					// Update file count if needed
+					if item.Handle.Info() != nil {
+						item.FileCount = len(item.Handle.Info().Files)
+					}
+
+					// Tidy away torrents that are done seeding
+					if autoRemoveDue(item, appConfig, now) {
+						autoRemoved = append(autoRemoved, item)
+					}
 
-				// Update file count if needed
-				if item.Handle.Info() != nil {
-					item.FileCount = len(item.Handle.Info().Files)
+					// Update last update timestamp
+					item.LastUpdate = now
 				}
+			})
 
-				// Update last update timestamp
-				item.LastUpdate = now
-			}
+			// Read the client-wide transfer totals
+			clientStats := client.Stats()
 
 			// Use fyne.Do to safely update UI from a goroutine
 			fyne.Do(func() {
@@ -919,15 +3260,75 @@ func main() {
 								Title:   "Download Complete",
 								Content: item.Name,
 							})
+							recordActivity(ActivityCompleted, hash, item.Name, fmt.Sprintf("Completed '%s'", item.Name))
+
+							// Let other tools know, without holding up the UI
+							if appConfig.WebhookEnabled && appConfig.WebhookURL != "" {
+								link, payload := appConfig.WebhookURL, newCompletionPayload(hash, item)
+								offlineErr := offlineError()
+								go func() {
+									err := offlineErr
+									if err == nil {
+										err = postWebhook(httpTransport, link, payload, func() error {
+											var err error
+											fyne.DoAndWait(func() {
+												err = offlineError()
+											})
+											return err
+										})
+									}
+									if err != nil {
+										log.Printf("Error sending completion webhook for '%s': %v", payload.Name, err)
+										fyne.Do(func() {
+											recordActivity(ActivityError, payload.InfoHash, payload.Name,
+												fmt.Sprintf("Couldn't send the completion webhook for '%s': %v", payload.Name, err))
+										})
+									}
+								}()
+							}
 						}
 					}
 				}
 
+				// Remove torrents that reached their seeding goal, keeping their data
+				for _, item := range autoRemoved {
+					if _, ok := torrentList[item.Handle.InfoHash().String()]; !ok {
+						continue
+					}
+					log.Printf("Automatically removing '%s', seeded since %s", item.Name, item.CompletedAt.Format("2006-01-02 15:04"))
+					recordActivity(ActivityCompleted, "", item.Name, fmt.Sprintf("Removed '%s' after it finished seeding", item.Name))
+					if appConfig.AutoRemoveNotify {
+						a.SendNotification(&fyne.Notification{
+							Title:   "Torrent Removed",
+							Content: fmt.Sprintf("%s finished seeding and was removed. Its files were kept.", item.Name),
+						})
+					}
+					removeTorrent(item, false)
+				}
+				if sessionChanged && len(autoRemoved) == 0 {
+					saveSession()
+				}
+
+				// Report pausing and resuming for the battery
+				if batteryPaused != wasBatteryPaused {
+					if batteryPaused {
+						log.Printf("Battery at %d%%, pausing all torrents", battery.Percent)
+						recordActivity(ActivityError, "", "Battery",
+							fmt.Sprintf("Battery at %d%%, paused all torrents until plugged in", battery.Percent))
+					} else {
+						log.Printf("Resuming all torrents after a low battery")
+						recordActivity(ActivityNetwork, "", "Battery", "Battery charging, resumed all torrents")
+					}
+				}
+
 				// Update status bar with totals
 				activeDownloads := 0
 				completedDownloads := 0
 				var totalDownloadRate int64
 				var totalUploadRate int64
+				var graphDownloadRate int64
+				totalPeers := 0
+				totalSeeds := 0
 
 				// Calculate counts and rates
 				for _, item := range torrentList {
@@ -935,6 +3336,10 @@ func main() {
 						continue
 					}
 
+					totalPeers += item.Peers
+					totalSeeds += item.Seeds
+					graphDownloadRate += item.DownloadRate
+
 					if item.Progress < 1.0 && item.Status != "Seeding" {
 						activeDownloads++
 						totalDownloadRate += item.DownloadRate
@@ -944,11 +3349,42 @@ func main() {
 					}
 				}
 
+				// Follow the speed limit schedule
+				applySpeedLimits()
+
+				swarmLabel.SetText(fmt.Sprintf("Peers: %d / Seeds: %d", totalPeers, totalSeeds))
+
+				// Keep recording while the graph is hidden so it is current when shown again
+				speedGraphHistory.Add(graphDownloadRate)
+				if appConfig.ShowSpeedGraph {
+					speedGraph.Refresh()
+				}
+
+				statisticsView.Update(clientStats.ConnStats, time.Now())
+				statisticsView.SetConnections(totalPeers, appConfig.MaxConnections)
+
+				// Warn while the all-time ratio is below the minimum
+				allTime := allTimeTransferred()
+				statisticsView.SetRatio(newTransferTotals(clientStats.ConnStats), allTime, appConfig.MinRatio)
+				if allTime.Below(appConfig.MinRatio) {
+					ratioBadge.SetText(fmt.Sprintf("Ratio %s, below %.2f", allTime.RatioString(), appConfig.MinRatio))
+					ratioBadge.Show()
+				} else {
+					ratioBadge.Hide()
+				}
+				queueTab.Refresh()
+				if mainTabs.Selected() == logTabItem {
+					logTab.Update()
+				}
+				libraryHeader.SetText(newLibrarySummary(torrentList).String())
+
 				// Update status bar text
 				if statusBar != nil && len(statusBar.Objects) > 0 {
 					statusLabel, ok := statusBar.Objects[0].(*widget.Label)
 					if ok && statusLabel != nil {
-						if activeDownloads > 0 {
+						if batteryPaused {
+							statusLabel.SetText(fmt.Sprintf("Status: Paused on battery (%d%%)", battery.Percent))
+						} else if activeDownloads > 0 {
 							statusLabel.SetText(fmt.Sprintf("Status: Downloading %d torrent(s) at %s, %d completed",
 								activeDownloads, HumanReadableRate(totalDownloadRate), completedDownloads))
 						} else if len(torrentList) > 0 {
@@ -962,7 +3398,7 @@ func main() {
 
 				// Refresh UI components
 				if list != nil {
-					list.Refresh()
+					refreshLibrary()
 				}
 
 				// Update details panel
@@ -976,4 +3412,9 @@ func main() {
 
 	// Show the window and run the app
 	w.ShowAndRun()
+
+	// Finish a removal that can no longer be undone, then save the final state of the
+	// torrents before the client closes
+	undoBar.Dismiss()
+	saveSession()
 }
@@ -0,0 +1,534 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anacrolix/torrent"
+	"golang.org/x/net/proxy"
+)
+
+// IPStack is which IP versions the client listens and connects on
+type IPStack string
+
+const (
+	IPStackDual IPStack = "dual"
+	IPStackIPv4 IPStack = "ipv4"
+	IPStackIPv6 IPStack = "ipv6"
+)
+
+// IPStackNames lists the stacks in the order they are offered in the UI
+var IPStackNames = []string{"IPv4 and IPv6", "IPv4 only", "IPv6 only"}
+
+// String returns the name shown in the UI
+func (s IPStack) String() string {
+	switch s {
+	case IPStackIPv4:
+		return "IPv4 only"
+	case IPStackIPv6:
+		return "IPv6 only"
+	}
+	return "IPv4 and IPv6"
+}
+
+// ParseIPStack converts a UI name back into an IPStack
+func ParseIPStack(name string) IPStack {
+	switch name {
+	case "IPv4 only":
+		return IPStackIPv4
+	case "IPv6 only":
+		return IPStackIPv6
+	}
+	return IPStackDual
+}
+
+// applyIPStack configures the anacrolix client to use only the chosen IP versions
+func applyIPStack(cfg *torrent.ClientConfig, stack IPStack) {
+	cfg.DisableIPv4 = stack == IPStackIPv6
+	cfg.DisableIPv6 = stack == IPStackIPv4
+}
+
+// activeIPStacks describes the IP versions the client is actually listening on. anacrolix
+// listens separately per version, on 0.0.0.0 for IPv4 and :: for IPv6.
+func activeIPStacks(addrs []net.Addr) string {
+	var ipv4, ipv6 bool
+	for _, addr := range addrs {
+		var ip net.IP
+		switch a := addr.(type) {
+		case *net.TCPAddr:
+			ip = a.IP
+		case *net.UDPAddr:
+			ip = a.IP
+		default:
+			continue
+		}
+		if ip.To4() != nil {
+			ipv4 = true
+		} else if ip != nil {
+			ipv6 = true
+		}
+	}
+
+	switch {
+	case ipv4 && ipv6:
+		return "IPv4 + IPv6"
+	case ipv4:
+		return "IPv4"
+	case ipv6:
+		return "IPv6"
+	}
+	return "not listening"
+}
+
+// ProxyType is the kind of proxy torrent traffic is sent through
+type ProxyType string
+
+const (
+	ProxyNone   ProxyType = ""
+	ProxySOCKS5 ProxyType = "socks5"
+	ProxyHTTP   ProxyType = "http"
+)
+
+// ProxyTypeNames lists the proxy types in the order they are offered in the UI
+var ProxyTypeNames = []string{"None", "SOCKS5", "HTTP"}
+
+// String returns the name shown in the UI
+func (t ProxyType) String() string {
+	switch t {
+	case ProxySOCKS5:
+		return "SOCKS5"
+	case ProxyHTTP:
+		return "HTTP"
+	}
+	return "None"
+}
+
+// ParseProxyType converts a UI name back into a ProxyType
+func ParseProxyType(name string) ProxyType {
+	switch name {
+	case "SOCKS5":
+		return ProxySOCKS5
+	case "HTTP":
+		return ProxyHTTP
+	}
+	return ProxyNone
+}
+
+// ProxySettings describe the proxy used for tracker, web seed and peer connections
+type ProxySettings struct {
+	Type     ProxyType `json:"type"`
+	Address  string    `json:"address"` // host:port of the proxy
+	Username string    `json:"username,omitempty"`
+	Password string    `json:"password,omitempty"`
+}
+
+// Enabled reports whether traffic should go through the proxy
+func (p ProxySettings) Enabled() bool {
+	return p.Type != ProxyNone
+}
+
+// URL returns the proxy as a URL, including its credentials
+func (p ProxySettings) URL() *url.URL {
+	u := &url.URL{Scheme: string(p.Type), Host: p.Address}
+	if p.Username != "" {
+		u.User = url.UserPassword(p.Username, p.Password)
+	}
+	return u
+}
+
+// validateProxyAddress accepts a host:port proxy address
+func validateProxyAddress(address string) error {
+	host, port, err := net.SplitHostPort(strings.TrimSpace(address))
+	if err != nil || host == "" {
+		return fmt.Errorf("enter the proxy as host:port")
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid proxy port %q", port)
+	}
+	return nil
+}
+
+// checkProxy makes sure the proxy accepts connections, so a typo isn't only noticed when
+// nothing downloads
+func checkProxy(p ProxySettings, timeout time.Duration) error {
+	conn, err := net.DialTimeout("tcp", p.Address, timeout)
+	if err != nil {
+		return fmt.Errorf("error connecting to proxy %s: %v", p.Address, err)
+	}
+	return conn.Close()
+}
+
+// proxyDialer returns a dialer that connects to peers through the proxy, reaching the proxy
+// itself with forward
+func proxyDialer(p ProxySettings, forward forwardDialer) (proxy.ContextDialer, error) {
+	switch p.Type {
+	case ProxySOCKS5:
+		var auth *proxy.Auth
+		if p.Username != "" {
+			auth = &proxy.Auth{User: p.Username, Password: p.Password}
+		}
+		d, err := proxy.SOCKS5("tcp", p.Address, auth, forward)
+		if err != nil {
+			return nil, err
+		}
+		return d.(proxy.ContextDialer), nil
+	case ProxyHTTP:
+		return httpConnectDialer{proxy: p, forward: forward}, nil
+	}
+	return nil, fmt.Errorf("unsupported proxy type %q", p.Type)
+}
+
+// applyProxy routes the client's traffic through the proxy. Trackers and web seeds use it as
+// an HTTP proxy. Peers can only be reached over TCP through a proxy, so uTP, the DHT, UDP
+// trackers and port forwarding are turned off rather than leaking the real address. The peer
+// dialer is added with addProxyDialer once the client exists.
+func applyProxy(cfg *torrent.ClientConfig, p ProxySettings) {
+	if !p.Enabled() {
+		return
+	}
+	cfg.HTTPProxy = http.ProxyURL(p.URL())
+	cfg.TrackerListenPacket = func(network, addr string) (net.PacketConn, error) {
+		return nil, fmt.Errorf("UDP trackers can't be used through a proxy")
+	}
+	// Without listening sockets anacrolix only dials peers with the proxy dialer
+	cfg.DisableTCP = true
+	cfg.DisableUTP = true
+	cfg.NoDHT = true
+	cfg.NoDefaultPortForwarding = true
+}
+
+// addProxyDialer makes the client dial peers through the proxy, reaching the proxy from the
+// bound address if there is one
+func addProxyDialer(client *torrent.Client, p ProxySettings, b BindAddress) error {
+	if !p.Enabled() {
+		return nil
+	}
+	d, err := proxyDialer(p, b.forward())
+	if err != nil {
+		return fmt.Errorf("error creating proxy dialer: %v", err)
+	}
+	client.AddDialer(torrent.NetworkDialer{Network: "tcp", Dialer: d})
+	return nil
+}
+
+// newHTTPTransport creates the transport for Reed's own HTTP requests, such as fetching
+// .torrent links and posting webhooks, so they go through the proxy and leave from the
+// bound address like the client's tracker traffic
+func newHTTPTransport(p ProxySettings, b BindAddress) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if b.Bound() {
+		transport.DialContext = b.DialContext
+	}
+	if p.Enabled() {
+		transport.Proxy = http.ProxyURL(p.URL())
+	}
+	return transport
+}
+
+// networkStatus describes how the client reaches the network for the status bar
+func networkStatus(addrs []net.Addr, p ProxySettings) string {
+	if p.Enabled() {
+		return fmt.Sprintf("Network: via %s proxy %s", p.Type, p.Address)
+	}
+	return fmt.Sprintf("Network: %s", activeIPStacks(addrs))
+}
+
+// forwardDialer is what proxy dialers use to reach the proxy itself
+type forwardDialer interface {
+	proxy.Dialer
+	proxy.ContextDialer
+}
+
+// httpConnectDialer opens connections through an HTTP proxy with the CONNECT method
+type httpConnectDialer struct {
+	proxy   ProxySettings
+	forward forwardDialer // Used to reach the proxy
+}
+
+// DialContext connects to addr through the proxy
+func (d httpConnectDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := d.forward.DialContext(ctx, "tcp", d.proxy.Address)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if d.proxy.Username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(d.proxy.Username + ":" + d.proxy.Password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy refused connection to %s: %s", addr, resp.Status)
+	}
+
+	conn.SetDeadline(time.Time{})
+	return &bufferedConn{Conn: conn, reader: reader}, nil
+}
+
+// bufferedConn is a connection whose first bytes may already be in a reader's buffer
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// Read reads from the buffer before the connection
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// BindAddress is the local address outgoing connections are made from, found from an
+// interface name or given directly as an IP
+type BindAddress struct {
+	Name string // The interface name or IP from the settings
+	IPv4 net.IP
+	IPv6 net.IP
+}
+
+// resolveBindAddress finds the addresses to bind to for an interface name or IP address
+func resolveBindAddress(name string) (BindAddress, error) {
+	b := BindAddress{Name: strings.TrimSpace(name)}
+	if ip := net.ParseIP(b.Name); ip != nil {
+		if ip.To4() != nil {
+			b.IPv4 = ip
+		} else {
+			b.IPv6 = ip
+		}
+		return b, nil
+	}
+
+	iface, err := net.InterfaceByName(b.Name)
+	if err != nil {
+		return BindAddress{}, fmt.Errorf("error finding interface %s: %v", b.Name, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return BindAddress{}, fmt.Errorf("error reading addresses of %s: %v", b.Name, err)
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			if b.IPv4 == nil {
+				b.IPv4 = ipNet.IP
+			}
+		} else if b.IPv6 == nil {
+			b.IPv6 = ipNet.IP
+		}
+	}
+	if b.IPv4 == nil && b.IPv6 == nil {
+		return BindAddress{}, fmt.Errorf("interface %s has no usable address", b.Name)
+	}
+	return b, nil
+}
+
+// Bound reports whether there is an address to bind to, the zero BindAddress meaning none
+func (b BindAddress) Bound() bool {
+	return b.Name != ""
+}
+
+// forward returns the dialer for connections that aren't to peers, such as to a proxy
+func (b BindAddress) forward() forwardDialer {
+	if !b.Bound() {
+		return proxy.Direct
+	}
+	return b
+}
+
+// Available reports whether the bound addresses still belong to an interface that is up,
+// which stops being true when a VPN disconnects
+func (b BindAddress) Available() bool {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return false
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if ok && (ipNet.IP.Equal(b.IPv4) || ipNet.IP.Equal(b.IPv6)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String describes the binding for the status bar
+func (b BindAddress) String() string {
+	ips := make([]string, 0, 2)
+	for _, ip := range []net.IP{b.IPv4, b.IPv6} {
+		if ip != nil {
+			ips = append(ips, ip.String())
+		}
+	}
+	if len(ips) == 1 && ips[0] == b.Name {
+		return b.Name
+	}
+	return fmt.Sprintf("%s (%s)", b.Name, strings.Join(ips, ", "))
+}
+
+// listenIP returns the bound address for a network such as tcp4 or udp6
+func (b BindAddress) listenIP(network string) net.IP {
+	if strings.HasSuffix(network, "6") {
+		return b.IPv6
+	}
+	if strings.HasSuffix(network, "4") || b.IPv4 != nil {
+		return b.IPv4
+	}
+	return b.IPv6
+}
+
+// DialContext connects from the bound addresses, trying IPv4 before IPv6
+func (b BindAddress) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	var lastErr error
+	for _, ip := range []net.IP{b.IPv4, b.IPv6} {
+		if ip == nil {
+			continue
+		}
+		var local net.Addr = &net.TCPAddr{IP: ip}
+		if strings.HasPrefix(network, "udp") {
+			local = &net.UDPAddr{IP: ip}
+		}
+		dialer := net.Dialer{LocalAddr: local}
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// Dial is DialContext without a context
+func (b BindAddress) Dial(network, addr string) (net.Conn, error) {
+	return b.DialContext(context.Background(), network, addr)
+}
+
+// ListenPacket opens a UDP socket on the bound address, for UDP trackers
+func (b BindAddress) ListenPacket(network, addr string) (net.PacketConn, error) {
+	ip := b.listenIP(network)
+	if ip == nil {
+		return nil, fmt.Errorf("%s has no address for %s", b.Name, network)
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		port = "0"
+	}
+	return net.ListenPacket(network, net.JoinHostPort(ip.String(), port))
+}
+
+// applyBinding makes the client listen on the bound addresses and connect to trackers and
+// web seeds from them. anacrolix doesn't bind its outgoing TCP connections to the listen
+// address, so its TCP sockets are turned off and addBoundTCP supplies bound ones instead.
+func applyBinding(cfg *torrent.ClientConfig, b BindAddress) {
+	cfg.DisableIPv4 = cfg.DisableIPv4 || b.IPv4 == nil
+	cfg.DisableIPv6 = cfg.DisableIPv6 || b.IPv6 == nil
+	cfg.ListenHost = func(network string) string {
+		if ip := b.listenIP(network); ip != nil {
+			return ip.String()
+		}
+		return ""
+	}
+	cfg.DisableTCP = true
+	cfg.HTTPDialContext = b.DialContext
+	cfg.TrackerDialContext = b.DialContext
+	cfg.TrackerListenPacket = b.ListenPacket
+}
+
+// addBoundTCP listens for and dials TCP peers on the bound addresses, on the client's port.
+// The returned listeners must be closed when the client is.
+func addBoundTCP(client *torrent.Client, cfg *torrent.ClientConfig, b BindAddress) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, 2)
+	for _, stack := range []struct {
+		network string
+		ip      net.IP
+		enabled bool
+	}{
+		{"tcp4", b.IPv4, !cfg.DisableIPv4},
+		{"tcp6", b.IPv6, !cfg.DisableIPv6},
+	} {
+		if stack.ip == nil || !stack.enabled {
+			continue
+		}
+		l, err := net.Listen(stack.network, net.JoinHostPort(stack.ip.String(), strconv.Itoa(client.LocalPort())))
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("error listening on %s: %v", stack.ip, err)
+		}
+		listeners = append(listeners, l)
+		client.AddListener(l)
+		client.AddDialer(torrent.NetworkDialer{Network: stack.network, Dialer: b})
+	}
+	return listeners, nil
+}
+
+// applyOffline stops the client from reaching the network at all, for when the kill-switch
+// is on but the interface to bind to can't be found
+func applyOffline(cfg *torrent.ClientConfig) {
+	offline := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, fmt.Errorf("offline until the bound interface is available")
+	}
+	cfg.DisableTCP = true
+	cfg.DisableUTP = true
+	cfg.NoDHT = true
+	cfg.NoDefaultPortForwarding = true
+	cfg.HTTPDialContext = offline
+	cfg.TrackerDialContext = offline
+	cfg.TrackerListenPacket = func(network, addr string) (net.PacketConn, error) {
+		return nil, fmt.Errorf("offline until the bound interface is available")
+	}
+}
+
+// interfaceNames lists the network interfaces that are up, for choosing one to bind to
+func interfaceNames() []string {
+	names := make([]string, 0)
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return names
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp != 0 && iface.Flags&net.FlagLoopback == 0 {
+			names = append(names, iface.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// matchesTorrent reports whether a search query is found in a torrent's name or note,
+// ignoring case
+func matchesTorrent(item *TorrentItem, query string) bool {
+	query = strings.ToLower(query)
+	return strings.Contains(strings.ToLower(item.Name), query) ||
+		strings.Contains(strings.ToLower(item.Note), query)
+}
+
+// notesView edits the free-text note of the torrent shown in the details panel
+type notesView struct {
+	Content fyne.CanvasObject
+
+	// OnChanged is called after the torrent's note has been saved
+	OnChanged func(item *TorrentItem)
+
+	item       *TorrentItem
+	noteInput  *widget.Entry
+	saveButton *widget.Button
+}
+
+// newNotesView creates a notes view showing no torrent
+func newNotesView() *notesView {
+	v := &notesView{}
+
+	v.noteInput = widget.NewMultiLineEntry()
+	v.noteInput.SetPlaceHolder("Why you downloaded this, where it came from, anything worth remembering")
+	v.noteInput.Wrapping = fyne.TextWrapWord
+
+	v.saveButton = widget.NewButtonWithIcon("Save Note", theme.DocumentSaveIcon(), func() {
+		if v.item == nil {
+			return
+		}
+		v.item.Note = strings.TrimSpace(v.noteInput.Text)
+		v.saveButton.Disable()
+		if v.OnChanged != nil {
+			v.OnChanged(v.item)
+		}
+	})
+	v.saveButton.Disable()
+
+	// Only offer to save once the note has been edited
+	v.noteInput.OnChanged = func(text string) {
+		if v.item != nil && strings.TrimSpace(text) != v.item.Note {
+			v.saveButton.Enable()
+		} else {
+			v.saveButton.Disable()
+		}
+	}
+
+	v.Content = container.NewBorder(
+		nil,
+		container.NewHBox(v.saveButton),
+		nil, nil,
+		v.noteInput,
+	)
+	return v
+}
+
+// SetTorrent shows the note of item
+func (v *notesView) SetTorrent(item *TorrentItem) {
+	v.item = item
+	if item == nil {
+		v.noteInput.SetText("")
+		return
+	}
+	v.noteInput.SetText(item.Note)
+}
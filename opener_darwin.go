@@ -0,0 +1,16 @@
+//go:build darwin
+// +build darwin
+
+package main
+
+import "os/exec"
+
+// openPath opens a file or folder with the default application
+func openPath(path string) error {
+	return exec.Command("open", path).Run()
+}
+
+// revealPath opens Finder with the given file selected
+func revealPath(path string) error {
+	return exec.Command("open", "-R", path).Run()
+}
@@ -0,0 +1,29 @@
+//go:build !windows && !darwin
+// +build !windows,!darwin
+
+package main
+
+import (
+	"net/url"
+	"os/exec"
+	"path/filepath"
+)
+
+// openPath opens a file or folder with the default application
+func openPath(path string) error {
+	return exec.Command("xdg-open", path).Start()
+}
+
+// revealPath asks the file manager to show the given file selected, falling back to
+// opening its folder when the file manager doesn't support that
+func revealPath(path string) error {
+	uri := (&url.URL{Scheme: "file", Path: path}).String()
+	err := exec.Command("dbus-send", "--session", "--print-reply",
+		"--dest=org.freedesktop.FileManager1", "--type=method_call",
+		"/org/freedesktop/FileManager1", "org.freedesktop.FileManager1.ShowItems",
+		"array:string:"+uri, "string:").Run()
+	if err == nil {
+		return nil
+	}
+	return openPath(filepath.Dir(path))
+}
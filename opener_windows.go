@@ -0,0 +1,23 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// openPath opens a file or folder with the default application
+func openPath(path string) error {
+	return exec.Command("explorer", path).Start()
+}
+
+// revealPath opens Explorer with the given file selected
+func revealPath(path string) error {
+	// Explorer needs the quotes after the comma, which exec would otherwise escape
+	cmd := exec.Command("explorer")
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: fmt.Sprintf(`explorer /select,"%s"`, path)}
+	return cmd.Start()
+}
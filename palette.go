@@ -0,0 +1,189 @@
+package main
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// paletteResultLimit is how many results the command palette lists at once
+const paletteResultLimit = 12
+
+// PaletteCommand is one action offered by the command palette
+type PaletteCommand struct {
+	Title string
+	Run   func()
+}
+
+// filterCommands returns the commands whose title contains every word of query, in any
+// case. An empty query matches everything.
+func filterCommands(commands []PaletteCommand, query string) []PaletteCommand {
+	words := strings.Fields(strings.ToLower(query))
+	matches := make([]PaletteCommand, 0)
+	for _, command := range commands {
+		title := strings.ToLower(command.Title)
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(title, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, command)
+		}
+	}
+	return matches
+}
+
+// paletteEntry is the palette's search entry, passing the keys that move through the
+// results to the palette instead of the text
+type paletteEntry struct {
+	widget.Entry
+
+	// OnKey is called before the entry handles a key, and returns whether it used it
+	OnKey func(*fyne.KeyEvent) bool
+}
+
+// newPaletteEntry creates an empty palette entry
+func newPaletteEntry() *paletteEntry {
+	e := &paletteEntry{}
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+// TypedKey implements fyne.Focusable
+func (e *paletteEntry) TypedKey(ev *fyne.KeyEvent) {
+	if e.OnKey != nil && e.OnKey(ev) {
+		return
+	}
+	e.Entry.TypedKey(ev)
+}
+
+// commandPalette is an overlay for running commands from the keyboard. The typed text
+// filters the fixed commands, and Suggest can offer more for it, such as adding a pasted
+// magnet link.
+type commandPalette struct {
+	// Commands are always offered, filtered by the typed text
+	Commands []PaletteCommand
+	// Suggest returns commands made from the typed text, listed before the fixed ones
+	Suggest func(text string) []PaletteCommand
+
+	canvas   fyne.Canvas
+	popup    *widget.PopUp
+	entry    *paletteEntry
+	list     *widget.List
+	results  []PaletteCommand
+	selected int
+}
+
+// newCommandPalette creates a hidden palette shown over canvas
+func newCommandPalette(canvas fyne.Canvas) *commandPalette {
+	p := &commandPalette{canvas: canvas}
+
+	p.entry = newPaletteEntry()
+	p.entry.SetPlaceHolder("Type a command, magnet link, info-hash or torrent name")
+	p.entry.OnChanged = p.update
+	p.entry.OnSubmitted = func(string) {
+		p.run(p.selected)
+	}
+	p.entry.OnKey = func(ev *fyne.KeyEvent) bool {
+		switch ev.Name {
+		case fyne.KeyEscape:
+			p.Hide()
+		case fyne.KeyDown:
+			p.selectResult(p.selected + 1)
+		case fyne.KeyUp:
+			p.selectResult(p.selected - 1)
+		default:
+			return false
+		}
+		return true
+	}
+
+	p.list = widget.NewList(
+		func() int {
+			return len(p.results)
+		},
+		func() fyne.CanvasObject {
+			button := widget.NewButton("Command", nil)
+			button.Alignment = widget.ButtonAlignLeading
+			return button
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if int(id) >= len(p.results) {
+				return
+			}
+			// Results are buttons so a click runs them, with the chosen one highlighted
+			button := obj.(*widget.Button)
+			button.SetText(p.results[id].Title)
+			button.OnTapped = func() {
+				p.run(int(id))
+			}
+			if int(id) == p.selected {
+				button.Importance = widget.HighImportance
+			} else {
+				button.Importance = widget.LowImportance
+			}
+			button.Refresh()
+		},
+	)
+
+	hint := widget.NewLabel("Enter to run, Up and Down to choose, Escape to close")
+	hint.Importance = widget.LowImportance
+
+	content := container.NewBorder(p.entry, hint, nil, nil, p.list)
+	p.popup = widget.NewModalPopUp(content, canvas)
+	return p
+}
+
+// Show opens the palette with an empty query
+func (p *commandPalette) Show() {
+	p.entry.SetText("")
+	p.update("")
+
+	size := p.canvas.Size()
+	p.popup.Resize(fyne.NewSize(min(size.Width-40, 600), min(size.Height-40, 420)))
+	p.popup.Show()
+	p.canvas.Focus(p.entry)
+}
+
+// Hide closes the palette
+func (p *commandPalette) Hide() {
+	p.popup.Hide()
+}
+
+// update lists the results for the typed text, selecting the first
+func (p *commandPalette) update(text string) {
+	p.results = nil
+	if p.Suggest != nil && strings.TrimSpace(text) != "" {
+		p.results = append(p.results, p.Suggest(strings.TrimSpace(text))...)
+	}
+	p.results = append(p.results, filterCommands(p.Commands, text)...)
+	if len(p.results) > paletteResultLimit {
+		p.results = p.results[:paletteResultLimit]
+	}
+
+	p.selectResult(0)
+}
+
+// selectResult highlights a result, keeping the selection within the list
+func (p *commandPalette) selectResult(index int) {
+	p.selected = max(0, min(index, len(p.results)-1))
+	p.list.Refresh()
+	if len(p.results) > 0 {
+		p.list.ScrollTo(p.selected)
+	}
+}
+
+// run closes the palette and runs a result
+func (p *commandPalette) run(index int) {
+	if index < 0 || index >= len(p.results) {
+		return
+	}
+	command := p.results[index]
+	p.Hide()
+	command.Run()
+}
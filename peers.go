@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/anacrolix/torrent"
+)
+
+// PeerInfo is a snapshot of one connected peer
+type PeerInfo struct {
+	Address      string
+	Client       string
+	Progress     float64 // Fraction of the torrent the peer has
+	DownloadRate int64   // Bytes per second received from the peer
+	Seed         bool    // Whether the peer has the whole torrent
+
+	conn *torrent.PeerConn
+}
+
+// newPeerInfos takes a snapshot of a torrent's connected peers, fastest first
+func newPeerInfos(t *torrent.Torrent) []PeerInfo {
+	numPieces := t.NumPieces()
+	peers := make([]PeerInfo, 0)
+	for _, pc := range t.PeerConns() {
+		p := PeerInfo{
+			Address:      pc.RemoteAddr.String(),
+			DownloadRate: int64(pc.DownloadRate()),
+			conn:         pc,
+		}
+		if name, ok := pc.PeerClientName.Load().(string); ok {
+			p.Client = name
+		}
+		if numPieces > 0 {
+			have := int(pc.PeerPieces().GetCardinality())
+			p.Progress = float64(have) / float64(numPieces)
+			p.Seed = have >= numPieces
+		}
+		peers = append(peers, p)
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].DownloadRate > peers[j].DownloadRate
+	})
+	return peers
+}
+
+// uploadSlotsInUse counts the peers Reed can upload to. anacrolix unchokes every interested
+// peer it is allowed to upload to, so each peer still missing part of the torrent holds a
+// slot, up to the limit (0 meaning unlimited).
+func uploadSlotsInUse(peers []PeerInfo, limit int) int {
+	inUse := 0
+	for _, p := range peers {
+		if !p.Seed {
+			inUse++
+		}
+	}
+	if limit > 0 && inUse > limit {
+		inUse = limit
+	}
+	return inUse
+}
+
+// preferSeedsProgress is how complete a torrent preferring seeds must be before it makes
+// room for them. Until then every peer helps, as most have pieces it still needs.
+const preferSeedsProgress = 0.9
+
+// peersToDrop picks connections to close in favour of seeds, given peers fastest first.
+// Seeds beyond maxSeeds (0 meaning no cap) go, slowest first. If preferSeeds is set and
+// the torrent has no free connection, the slowest peer that isn't a seed goes too, so
+// anacrolix can connect to another peer in its place, which may be a seed. anacrolix has
+// no way to pick which peers it connects to, so this is as close as Reed can get.
+func peersToDrop(peers []PeerInfo, maxSeeds int, preferSeeds, full bool) []PeerInfo {
+	drop := make([]PeerInfo, 0)
+	seeds := 0
+	var slowest *PeerInfo
+	for i := range peers {
+		if !peers[i].Seed {
+			slowest = &peers[i]
+			continue
+		}
+		seeds++
+		if maxSeeds > 0 && seeds > maxSeeds {
+			drop = append(drop, peers[i])
+		}
+	}
+	if preferSeeds && full && slowest != nil && (maxSeeds == 0 || seeds < maxSeeds) {
+		drop = append(drop, *slowest)
+	}
+	return drop
+}
+
+// validateMaxSeeds accepts a whole number of seeds, 0 meaning no cap
+func validateMaxSeeds(text string) error {
+	seeds, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || seeds < 0 {
+		return fmt.Errorf("enter a whole number of seeds (0 for no cap)")
+	}
+	return nil
+}
+
+// parseMaxSeeds reads a validated seed cap
+func parseMaxSeeds(text string) int {
+	seeds, _ := strconv.Atoi(strings.TrimSpace(text))
+	return seeds
+}
+
+// validateUploadSlots accepts a whole number of upload slots, 0 meaning no limit of its own
+func validateUploadSlots(text string) error {
+	slots, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || slots < 0 {
+		return fmt.Errorf("enter a whole number of slots (0 for no limit)")
+	}
+	return nil
+}
+
+// parseUploadSlots reads a validated upload slot count
+func parseUploadSlots(text string) int {
+	slots, _ := strconv.Atoi(strings.TrimSpace(text))
+	return slots
+}
+
+// effectiveUploadSlots returns the upload slot limit for a torrent: its own limit if it
+// has one, otherwise the global one, 0 meaning unlimited
+func effectiveUploadSlots(item *TorrentItem, config *Config) int {
+	if item.UploadSlots > 0 {
+		return item.UploadSlots
+	}
+	return config.UploadSlots
+}
+
+// peersView lists the peers of the torrent shown in the details panel and edits its upload
+// slot limit and how it treats seeds
+type peersView struct {
+	Content fyne.CanvasObject
+
+	// Columns holds the widths of the peer list's columns
+	Columns *listColumns
+
+	// OnSlotsChanged is called after the torrent's upload slot limit has been changed
+	OnSlotsChanged func(item *TorrentItem)
+
+	// OnSeedsChanged is called after the torrent's seed cap or seed preference has been changed
+	OnSeedsChanged func(item *TorrentItem)
+
+	item         *TorrentItem
+	slotsInput   *widget.Entry
+	seedsInput   *widget.Entry
+	preferCheck  *widget.Check
+	summaryLabel *widget.Label
+	list         *widget.List
+	peers        []PeerInfo
+}
+
+// newPeersView creates a peers view showing no torrent, its columns at columnWidths
+func newPeersView(columnWidths []float32) *peersView {
+	v := &peersView{
+		Columns:      newListColumns(columnWidths, -1),
+		summaryLabel: widget.NewLabel(""),
+	}
+
+	v.slotsInput = widget.NewEntry()
+	v.slotsInput.SetPlaceHolder("0 = use the global setting")
+	v.slotsInput.Validator = validateUploadSlots
+	applyButton := widget.NewButton("Apply", func() {
+		if v.item == nil || v.slotsInput.Validate() != nil {
+			return
+		}
+		v.item.UploadSlots = parseUploadSlots(v.slotsInput.Text)
+		if v.OnSlotsChanged != nil {
+			v.OnSlotsChanged(v.item)
+		}
+	})
+	v.slotsInput.SetOnValidationChanged(func(err error) {
+		if err != nil {
+			applyButton.Disable()
+		} else {
+			applyButton.Enable()
+		}
+	})
+
+	v.seedsInput = widget.NewEntry()
+	v.seedsInput.SetPlaceHolder("0 = no cap")
+	v.seedsInput.Validator = validateMaxSeeds
+	seedsButton := widget.NewButton("Apply", func() {
+		if v.item == nil || v.seedsInput.Validate() != nil {
+			return
+		}
+		v.item.MaxSeeds = parseMaxSeeds(v.seedsInput.Text)
+		if v.OnSeedsChanged != nil {
+			v.OnSeedsChanged(v.item)
+		}
+	})
+	v.seedsInput.SetOnValidationChanged(func(err error) {
+		if err != nil {
+			seedsButton.Disable()
+		} else {
+			seedsButton.Enable()
+		}
+	})
+	seedsItem := widget.NewFormItem("Max Seeds", container.NewBorder(nil, nil, nil, seedsButton, v.seedsInput))
+	seedsItem.HintText = "Seed connections kept at once; slower seeds beyond this are dropped"
+
+	v.preferCheck = widget.NewCheck(fmt.Sprintf("Prefer seeds once %.0f%% complete", preferSeedsProgress*100), nil)
+	preferItem := widget.NewFormItem("", v.preferCheck)
+	preferItem.HintText = "While every connection is in use, the slowest peer that isn't a seed is dropped to make room"
+
+	v.list = widget.NewList(
+		func() int {
+			return len(v.peers)
+		},
+		func() fyne.CanvasObject {
+			cells := make([]fyne.CanvasObject, 0, 4)
+			for _, text := range []string{"Address", "Client", "Progress", "Speed"} {
+				label := widget.NewLabel(text)
+				label.Truncation = fyne.TextTruncateEllipsis
+				cells = append(cells, label)
+			}
+			return v.Columns.Row(cells...)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if int(id) >= len(v.peers) {
+				return
+			}
+			p := v.peers[id]
+			row := obj.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(p.Address)
+			row.Objects[1].(*widget.Label).SetText(p.Client)
+			progress := fmt.Sprintf("%.1f%%", p.Progress*100)
+			if p.Seed {
+				progress = "Seed"
+			}
+			row.Objects[2].(*widget.Label).SetText(progress)
+			row.Objects[3].(*widget.Label).SetText(HumanReadableRate(p.DownloadRate))
+		},
+	)
+
+	v.Content = container.NewBorder(
+		container.NewVBox(
+			widget.NewForm(
+				widget.NewFormItem("Upload Slots", container.NewBorder(nil, nil, nil, applyButton, v.slotsInput)),
+				seedsItem,
+				preferItem,
+			),
+			v.summaryLabel,
+			widget.NewSeparator(),
+			v.Columns.Header("Address", "Client", "Progress", "Speed"),
+		),
+		nil, nil, nil,
+		v.list,
+	)
+	return v
+}
+
+// SetTorrent shows the peers and upload slot limit of item
+func (v *peersView) SetTorrent(item *TorrentItem) {
+	v.item = item
+	v.peers = nil
+	v.slotsInput.SetText("0")
+	v.seedsInput.SetText("0")
+
+	// Detach the handler while showing the torrent's choice so it doesn't fire
+	v.preferCheck.OnChanged = nil
+	v.preferCheck.SetChecked(false)
+	if item != nil {
+		v.slotsInput.SetText(strconv.Itoa(item.UploadSlots))
+		v.seedsInput.SetText(strconv.Itoa(item.MaxSeeds))
+		v.preferCheck.SetChecked(item.PreferSeeds)
+	}
+	v.preferCheck.OnChanged = func(checked bool) {
+		if v.item == nil {
+			return
+		}
+		v.item.PreferSeeds = checked
+		if v.OnSeedsChanged != nil {
+			v.OnSeedsChanged(v.item)
+		}
+	}
+	v.summaryLabel.SetText("")
+	v.list.UnselectAll()
+	v.list.Refresh()
+}
+
+// Update shows a new snapshot of peers. slotLimit is the upload slot limit in effect
+// (0 meaning unlimited).
+func (v *peersView) Update(peers []PeerInfo, slotLimit int) {
+	v.peers = peers
+
+	limit := "unlimited"
+	if slotLimit > 0 {
+		limit = strconv.Itoa(slotLimit)
+	}
+	seeds := 0
+	for _, p := range peers {
+		if p.Seed {
+			seeds++
+		}
+	}
+	summary := fmt.Sprintf("%d peer(s) connected, %d of them seeds, upload slots: %d in use of %s",
+		len(peers), seeds, uploadSlotsInUse(peers, slotLimit), limit)
+	if v.item != nil && v.item.peersDropped > 0 {
+		summary += fmt.Sprintf(". %d connection(s) dropped in favour of seeds", v.item.peersDropped)
+	}
+	v.summaryLabel.SetText(summary)
+	v.list.Refresh()
+}
+
+// connectionShare splits a limit on connections across all torrents evenly, so each of
+// torrents gets at least one. 0 means unlimited.
+func connectionShare(limit, torrents int) int {
+	if limit <= 0 {
+		return 0
+	}
+	return max(limit/max(torrents, 1), 1)
+}
+
+// validateMaxConnections accepts a whole number of connections, 0 meaning no limit
+func validateMaxConnections(text string) error {
+	limit, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || limit < 0 {
+		return fmt.Errorf("enter a whole number of connections (0 for no limit)")
+	}
+	return nil
+}
+
+// parseMaxConnections reads a validated connection limit
+func parseMaxConnections(text string) int {
+	limit, _ := strconv.Atoi(strings.TrimSpace(text))
+	return limit
+}
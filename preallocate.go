@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// zeroFillChunk is how much is written at a time when space is reserved by writing zeros
+const zeroFillChunk = 1 << 20
+
+// preallocateFiles reserves the full size of a torrent's selected files on disk, so their
+// data is laid out in one piece and a full disk shows up before the download starts
+// rather than part way through. Files that are already full size are left alone.
+func preallocateFiles(item *TorrentItem) error {
+	for i, f := range item.Handle.Files() {
+		if strings.Contains(f.FileInfo().Attr, "p") || i >= len(item.Files) || !item.Files[i].Selected {
+			continue
+		}
+		path := dataFilePath(item, i)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("error creating folder for %s: %v", path, err)
+		}
+		if err := preallocateFile(path, f.Length()); err != nil {
+			return fmt.Errorf("error preallocating %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// zeroFill extends f to size by writing zeros after its current end, for filesystems
+// that can't reserve space without writing it
+func zeroFill(f *os.File, size int64) error {
+	st, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	zeros := make([]byte, zeroFillChunk)
+	for offset := st.Size(); offset < size; offset += zeroFillChunk {
+		n := min(size-offset, zeroFillChunk)
+		if _, err := f.WriteAt(zeros[:n], offset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import "os"
+
+// preallocateFile reserves size bytes for the file at path by writing zeros after the
+// data already in it
+func preallocateFile(path string, size int64) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return zeroFill(f, size)
+}
@@ -0,0 +1,60 @@
+package main
+
+// TorrentPriority is how much preference a whole torrent gets over the others
+type TorrentPriority string
+
+const (
+	TorrentPriorityNormal TorrentPriority = "normal"
+	TorrentPriorityHigh   TorrentPriority = "high"
+	TorrentPriorityLow    TorrentPriority = "low"
+)
+
+// TorrentPriorityNames lists the priorities in the order they are offered in the UI
+var TorrentPriorityNames = []string{"High", "Normal", "Low"}
+
+// String returns the name shown in the UI
+func (p TorrentPriority) String() string {
+	switch p {
+	case TorrentPriorityHigh:
+		return "High"
+	case TorrentPriorityLow:
+		return "Low"
+	}
+	return "Normal"
+}
+
+// ParseTorrentPriority converts a UI name back into a TorrentPriority
+func ParseTorrentPriority(name string) TorrentPriority {
+	switch name {
+	case "High":
+		return TorrentPriorityHigh
+	case "Low":
+		return TorrentPriorityLow
+	}
+	return TorrentPriorityNormal
+}
+
+// rank orders priorities in the queue, lowest first. Torrents saved before priorities
+// existed count as normal.
+func (p TorrentPriority) rank() int {
+	switch p {
+	case TorrentPriorityHigh:
+		return 0
+	case TorrentPriorityLow:
+		return 2
+	}
+	return 1
+}
+
+// connLimit scales the per-torrent connection limit by priority. anacrolix shares one rate
+// limit between all torrents, so more connections is how a torrent gets a bigger share of
+// the bandwidth.
+func (p TorrentPriority) connLimit(base int) int {
+	switch p {
+	case TorrentPriorityHigh:
+		return base * 2
+	case TorrentPriorityLow:
+		return max(base/2, 1)
+	}
+	return base
+}
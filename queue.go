@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// QueueOrder is how torrents are ordered in the queue
+type QueueOrder string
+
+const (
+	QueueOrderPriority QueueOrder = "priority" // High priority first, then in the order added
+	QueueOrderManual   QueueOrder = "manual"   // The order set in the Queue tab
+)
+
+// QueueOrderNames lists the orders in the order they are offered in the UI
+var QueueOrderNames = []string{"Priority, then date added", "Manual"}
+
+// String returns the name shown in the UI
+func (o QueueOrder) String() string {
+	if o == QueueOrderManual {
+		return "Manual"
+	}
+	return "Priority, then date added"
+}
+
+// ParseQueueOrder converts a UI name back into a QueueOrder
+func ParseQueueOrder(name string) QueueOrder {
+	if name == "Manual" {
+		return QueueOrderManual
+	}
+	return QueueOrderPriority
+}
+
+// sortedInfoHashes returns the info-hashes of the torrents in queue order
+func sortedInfoHashes(torrents map[string]*TorrentItem, order QueueOrder) []string {
+	hashes := make([]string, 0, len(torrents))
+	for hash := range torrents {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		a, b := torrents[hashes[i]], torrents[hashes[j]]
+		if a != nil && b != nil {
+			if order == QueueOrderManual && a.QueuePosition != b.QueuePosition {
+				return a.QueuePosition < b.QueuePosition
+			}
+			if order != QueueOrderManual && a.Priority.rank() != b.Priority.rank() {
+				return a.Priority.rank() < b.Priority.rank()
+			}
+			if !a.AddedAt.Equal(b.AddedAt) {
+				return a.AddedAt.Before(b.AddedAt)
+			}
+		}
+		return hashes[i] < hashes[j]
+	})
+	return hashes
+}
+
+// nextQueuePosition returns the position that puts a new torrent at the end of the queue
+func nextQueuePosition(torrents map[string]*TorrentItem) int {
+	next := 0
+	for _, item := range torrents {
+		if item != nil && item.QueuePosition >= next {
+			next = item.QueuePosition + 1
+		}
+	}
+	return next
+}
+
+// moveInQueue moves a torrent delta places in the manual order. The positions are first
+// renumbered from the current order, so the first move keeps the order the user sees.
+func moveInQueue(torrents map[string]*TorrentItem, order QueueOrder, hash string, delta int) bool {
+	hashes := sortedInfoHashes(torrents, order)
+	from := -1
+	for i, h := range hashes {
+		if h == hash {
+			from = i
+		}
+	}
+	to := from + delta
+	if from < 0 || to < 0 || to >= len(hashes) {
+		return false
+	}
+
+	hashes[from], hashes[to] = hashes[to], hashes[from]
+	for i, h := range hashes {
+		torrents[h].QueuePosition = i
+	}
+	return true
+}
+
+// wantsDownload reports whether a torrent takes up an active download slot
+func wantsDownload(item *TorrentItem) bool {
+	return item.Handle != nil && item.Handle.Info() != nil && item.Progress < 1.0 && !item.MetadataOnly && !item.Checking &&
+		!item.Paused
+}
+
+// queuedTorrents returns the torrents that must wait because the first limit downloads in
+// queue order are already active, 0 meaning no limit
+func queuedTorrents(torrents map[string]*TorrentItem, order QueueOrder, limit int) map[string]bool {
+	queued := make(map[string]bool)
+	if limit <= 0 {
+		return queued
+	}
+	active := 0
+	for _, hash := range sortedInfoHashes(torrents, order) {
+		item := torrents[hash]
+		if item == nil || !wantsDownload(item) {
+			continue
+		}
+		if active < limit {
+			active++
+		} else {
+			queued[hash] = true
+		}
+	}
+	return queued
+}
+
+// validateActiveDownloads accepts a whole number of downloads, 0 meaning no limit
+func validateActiveDownloads(text string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || n < 0 {
+		return fmt.Errorf("enter a whole number of downloads (0 for no limit)")
+	}
+	return nil
+}
+
+// parseActiveDownloads reads a validated active download limit
+func parseActiveDownloads(text string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(text))
+	return n
+}
+
+// queueView lists the torrents in queue order with controls to move them, since Fyne lists
+// can't be reordered by dragging
+type queueView struct {
+	Content fyne.CanvasObject
+
+	// OnChanged is called after the order or the way it is decided has changed
+	OnChanged func()
+
+	torrents     map[string]*TorrentItem
+	config       *Config
+	hashes       []string
+	orderInput   *widget.Select
+	summaryLabel *widget.Label
+	list         *widget.List
+}
+
+// newQueueView creates a queue view of torrents, ordered as config says
+func newQueueView(torrents map[string]*TorrentItem, config *Config) *queueView {
+	v := &queueView{
+		torrents:     torrents,
+		config:       config,
+		summaryLabel: widget.NewLabel(""),
+	}
+
+	v.orderInput = widget.NewSelect(QueueOrderNames, func(name string) {
+		if order := ParseQueueOrder(name); order != v.config.QueueOrder {
+			v.setOrder(order)
+		}
+	})
+	v.orderInput.SetSelected(config.QueueOrder.String())
+
+	v.list = widget.NewList(
+		func() int {
+			return len(v.hashes)
+		},
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil,
+				widget.NewLabel("000"),
+				container.NewHBox(
+					widget.NewLabel("Status"),
+					widget.NewButtonWithIcon("", theme.MoveUpIcon(), nil),
+					widget.NewButtonWithIcon("", theme.MoveDownIcon(), nil),
+				),
+				widget.NewLabel("Torrent Name"),
+			)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if int(id) >= len(v.hashes) {
+				return
+			}
+			hash := v.hashes[id]
+			item := v.torrents[hash]
+			if item == nil {
+				return
+			}
+
+			row := obj.(*fyne.Container)
+			nameLabel := row.Objects[0].(*widget.Label)
+			positionLabel := row.Objects[1].(*widget.Label)
+			right := row.Objects[2].(*fyne.Container)
+			statusLabel := right.Objects[0].(*widget.Label)
+			upButton := right.Objects[1].(*widget.Button)
+			downButton := right.Objects[2].(*widget.Button)
+
+			positionLabel.SetText(fmt.Sprintf("%d.", id+1))
+			nameLabel.SetText(item.Name)
+			statusLabel.SetText(item.Status)
+
+			upButton.OnTapped = func() {
+				v.move(hash, -1)
+			}
+			downButton.OnTapped = func() {
+				v.move(hash, 1)
+			}
+			if id == 0 {
+				upButton.Disable()
+			} else {
+				upButton.Enable()
+			}
+			if int(id) == len(v.hashes)-1 {
+				downButton.Disable()
+			} else {
+				downButton.Enable()
+			}
+		},
+	)
+
+	hint := widget.NewLabel("Moving a torrent switches to the manual order. Downloads start from the top " +
+		"of the queue when the active download limit in Settings is reached.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	v.Content = container.NewBorder(
+		container.NewVBox(
+			widget.NewForm(widget.NewFormItem("Order", v.orderInput)),
+			v.summaryLabel,
+			widget.NewSeparator(),
+		),
+		hint,
+		nil, nil,
+		v.list,
+	)
+	return v
+}
+
+// setOrder changes how the queue is ordered
+func (v *queueView) setOrder(order QueueOrder) {
+	v.config.QueueOrder = order
+	v.orderInput.SetSelected(order.String())
+	v.Refresh()
+	if v.OnChanged != nil {
+		v.OnChanged()
+	}
+}
+
+// move shifts a torrent up or down the queue, switching to the manual order
+func (v *queueView) move(hash string, delta int) {
+	if !moveInQueue(v.torrents, v.config.QueueOrder, hash, delta) {
+		return
+	}
+	v.setOrder(QueueOrderManual)
+}
+
+// Refresh shows the current queue
+func (v *queueView) Refresh() {
+	v.hashes = sortedInfoHashes(v.torrents, v.config.QueueOrder)
+
+	downloading, queued := 0, 0
+	for _, hash := range v.hashes {
+		item := v.torrents[hash]
+		if item == nil || !wantsDownload(item) {
+			continue
+		}
+		if item.Queued {
+			queued++
+		} else {
+			downloading++
+		}
+	}
+	limit := "no limit"
+	if v.config.MaxActiveDownloads > 0 {
+		limit = fmt.Sprintf("limit %d", v.config.MaxActiveDownloads)
+	}
+	v.summaryLabel.SetText(fmt.Sprintf("%d downloading, %d queued (%s)", downloading, queued, limit))
+	v.list.Refresh()
+}
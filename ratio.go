@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/anacrolix/torrent"
+)
+
+// TransferTotals are the bytes of torrent data sent and received, leaving out protocol
+// overhead, the way trackers count them for a share ratio
+type TransferTotals struct {
+	Uploaded   int64 `json:"uploaded"`
+	Downloaded int64 `json:"downloaded"`
+}
+
+// newTransferTotals reads the payload bytes of anacrolix connection stats
+func newTransferTotals(stats torrent.ConnStats) TransferTotals {
+	return TransferTotals{
+		Uploaded:   stats.BytesWrittenData.Int64(),
+		Downloaded: stats.BytesReadData.Int64(),
+	}
+}
+
+// Add returns the sum of two totals
+func (t TransferTotals) Add(other TransferTotals) TransferTotals {
+	return TransferTotals{Uploaded: t.Uploaded + other.Uploaded, Downloaded: t.Downloaded + other.Downloaded}
+}
+
+// Ratio returns the bytes uploaded for each byte downloaded. It is false while nothing
+// has been downloaded, as the ratio is undefined.
+func (t TransferTotals) Ratio() (float64, bool) {
+	if t.Downloaded <= 0 {
+		return 0, false
+	}
+	return float64(t.Uploaded) / float64(t.Downloaded), true
+}
+
+// RatioString formats the ratio, such as "1.25", or "∞" when data was only uploaded
+func (t TransferTotals) RatioString() string {
+	ratio, ok := t.Ratio()
+	switch {
+	case ok:
+		return fmt.Sprintf("%.2f", ratio)
+	case t.Uploaded > 0:
+		return "∞"
+	default:
+		return "-"
+	}
+}
+
+// Below reports whether the ratio falls short of minimum, 0 meaning there is no minimum.
+// Nothing downloaded can't be below it.
+func (t TransferTotals) Below(minimum float64) bool {
+	ratio, ok := t.Ratio()
+	return minimum > 0 && ok && ratio < minimum
+}
+
+// String describes the totals with their ratio, such as "1.25 (↑ 5.0 GB / ↓ 4.0 GB)"
+func (t TransferTotals) String() string {
+	return fmt.Sprintf("%s (↑ %s / ↓ %s)", t.RatioString(), HumanReadableSize(t.Uploaded), HumanReadableSize(t.Downloaded))
+}
+
+// sortByRatio reorders hashes so the torrents with the lowest ratio, which most need
+// seeding, come first. Torrents that downloaded nothing go last, and ties keep their order.
+func sortByRatio(hashes []string, torrents map[string]*TorrentItem) {
+	sort.SliceStable(hashes, func(i, j int) bool {
+		a, aok := torrents[hashes[i]].Transferred.Ratio()
+		b, bok := torrents[hashes[j]].Transferred.Ratio()
+		if aok != bok {
+			return aok
+		}
+		return a < b
+	})
+}
+
+// validateMinRatio accepts a ratio of 0 or more, 0 meaning no minimum. NaN and infinity
+// are refused, as the settings file can't hold them.
+func validateMinRatio(text string) error {
+	ratio, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil || ratio < 0 || math.IsNaN(ratio) || math.IsInf(ratio, 0) {
+		return fmt.Errorf("enter a ratio such as 1.0 (0 for no minimum)")
+	}
+	return nil
+}
+
+// parseMinRatio reads a validated minimum ratio
+func parseMinRatio(text string) float64 {
+	ratio, _ := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	return ratio
+}
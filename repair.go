@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/anacrolix/torrent"
+)
+
+// RepairResult counts what an import of data from another folder achieved
+type RepairResult struct {
+	Missing  int // Pieces that were missing before the import
+	Filled   int // Missing pieces that were filled with matching data
+	Mismatch int // Pieces found in the folder whose data didn't match the torrent
+}
+
+// repairSourcePath returns where file index of a torrent would be in a folder holding
+// another copy of its data. The copy may be the torrent's own folder, or a folder that
+// contains it under the torrent's name.
+func repairSourcePath(t *torrent.Torrent, dir string, index int) string {
+	info := t.Info()
+	if len(info.Files) == 0 {
+		if st, err := os.Stat(dir); err == nil && !st.IsDir() {
+			return dir
+		}
+		return filepath.Join(dir, info.BestName())
+	}
+
+	root := dir
+	if st, err := os.Stat(filepath.Join(dir, info.BestName())); err == nil && st.IsDir() {
+		root = filepath.Join(dir, info.BestName())
+	}
+	fileInfo := t.Files()[index].FileInfo()
+	return filepath.Join(root, filepath.Join(fileInfo.BestPath()...))
+}
+
+// isPaddingFile reports whether f only pads the next file to a piece boundary. Padding is
+// all zeroes and never stored on disk, so it is neither read from a folder nor written.
+func isPaddingFile(f *torrent.File) bool {
+	return strings.Contains(f.FileInfo().Attr, "p")
+}
+
+// fileCache keeps files open while pieces spanning them are read or written
+type fileCache struct {
+	flag  int
+	files map[string]*os.File
+}
+
+// open returns the file at path, opening it the first time
+func (c *fileCache) open(path string) (*os.File, error) {
+	if f, ok := c.files[path]; ok {
+		return f, nil
+	}
+	if c.flag&os.O_CREATE != 0 {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+	}
+	f, err := os.OpenFile(path, c.flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+	c.files[path] = f
+	return f, nil
+}
+
+// close closes every open file
+func (c *fileCache) close() {
+	for _, f := range c.files {
+		f.Close()
+	}
+}
+
+// repairFromFolder fills the missing pieces of a torrent with data read from another copy
+// of its files in dir. Each piece is only written once its data matches the piece hash,
+// and anacrolix then verifies it again before counting it as complete. Closing cancel
+// stops the import between pieces; progress is called after each missing piece.
+func repairFromFolder(item *TorrentItem, dir string, cancel <-chan struct{}, progress func(done, total int)) (RepairResult, error) {
+	var result RepairResult
+	t := item.Handle
+	info := t.Info()
+	if info == nil {
+		return result, fmt.Errorf("torrent information not available yet")
+	}
+	files := t.Files()
+
+	missing := make([]int, 0)
+	for i := 0; i < t.NumPieces(); i++ {
+		if !t.Piece(i).State().Complete {
+			missing = append(missing, i)
+		}
+	}
+	result.Missing = len(missing)
+
+	sources := &fileCache{flag: os.O_RDONLY, files: make(map[string]*os.File)}
+	defer sources.close()
+	targets := &fileCache{flag: os.O_WRONLY | os.O_CREATE, files: make(map[string]*os.File)}
+	defer targets.close()
+
+	for done, index := range missing {
+		select {
+		case <-cancel:
+			return result, nil
+		default:
+		}
+
+		piece := info.Piece(index)
+		hash := piece.V1Hash()
+		if !hash.Ok {
+			return result, fmt.Errorf("only BitTorrent v1 torrents can be repaired from a folder")
+		}
+
+		// Read the piece from the files it spans, skipping it if any of them is missing.
+		// Padding is left as the zeroes the buffer starts with.
+		start, end := piece.Offset(), piece.Offset()+piece.Length()
+		data := make([]byte, piece.Length())
+		complete := true
+		for i, f := range files {
+			from, to := max(start, f.Offset()), min(end, f.Offset()+f.Length())
+			if from >= to || isPaddingFile(f) {
+				continue
+			}
+			src, err := sources.open(repairSourcePath(t, dir, i))
+			if err != nil {
+				complete = false
+				break
+			}
+			if _, err := src.ReadAt(data[from-start:to-start], from-f.Offset()); err != nil {
+				complete = false
+				break
+			}
+		}
+
+		if complete {
+			sum := sha1.Sum(data)
+			if !bytes.Equal(sum[:], hash.Value[:]) {
+				result.Mismatch++
+			} else {
+				// Write the matching data where the torrent stores it, then have anacrolix
+				// verify the piece so it counts as complete
+				for i, f := range files {
+					from, to := max(start, f.Offset()), min(end, f.Offset()+f.Length())
+					if from >= to || isPaddingFile(f) {
+						continue
+					}
+					dst, err := targets.open(dataFilePath(item, i))
+					if err != nil {
+						return result, fmt.Errorf("error opening %s: %v", dataFilePath(item, i), err)
+					}
+					if _, err := dst.WriteAt(data[from-start:to-start], from-f.Offset()); err != nil {
+						return result, fmt.Errorf("error writing %s: %v", dataFilePath(item, i), err)
+					}
+				}
+				for _, f := range targets.files {
+					f.Sync()
+				}
+				t.Piece(index).VerifyData()
+				if t.Piece(index).State().Complete {
+					result.Filled++
+				}
+			}
+		}
+
+		if progress != nil {
+			progress(done+1, len(missing))
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/anacrolix/torrent/storage"
+)
+
+// DefaultSavePathTemplate stores each torrent directly in the data dir under its own name
+const DefaultSavePathTemplate = "{name}"
+
+// SavePathPlaceholder describes a placeholder that can be used in a save path template
+type SavePathPlaceholder struct {
+	Name        string
+	Description string
+}
+
+// SavePathPlaceholders lists the placeholders understood by RenderSavePath
+var SavePathPlaceholders = []SavePathPlaceholder{
+	{Name: "{name}", Description: "Torrent name"},
+	{Name: "{category}", Description: "Category (left out when the torrent has none)"},
+	{Name: "{hash}", Description: "Info-hash in hex"},
+}
+
+// splitTemplate breaks a template into path elements, accepting either separator
+func splitTemplate(tmpl string) []string {
+	return strings.FieldsFunc(tmpl, func(r rune) bool {
+		return r == '/' || r == '\\'
+	})
+}
+
+// ValidateSavePathTemplate checks that a template is a relative path built from known
+// placeholders whose last element contains {name}
+func ValidateSavePathTemplate(tmpl string) error {
+	tmpl = strings.TrimSpace(tmpl)
+	if tmpl == "" {
+		return fmt.Errorf("template is empty")
+	}
+	if filepath.IsAbs(tmpl) || strings.HasPrefix(tmpl, "/") || strings.HasPrefix(tmpl, "\\") {
+		return fmt.Errorf("template must be a relative path")
+	}
+
+	elements := splitTemplate(tmpl)
+	for _, element := range elements {
+		if element == "." || element == ".." {
+			return fmt.Errorf("template must not contain %q", element)
+		}
+
+		// Every opening brace must start a known placeholder
+		rest := element
+		for {
+			open := strings.IndexAny(rest, "{}")
+			if open < 0 {
+				break
+			}
+			if rest[open] == '}' {
+				return fmt.Errorf("unmatched '}' in %q", element)
+			}
+			end := strings.Index(rest[open:], "}")
+			if end < 0 {
+				return fmt.Errorf("unmatched '{' in %q", element)
+			}
+			placeholder := rest[open : open+end+1]
+			if !isSavePathPlaceholder(placeholder) {
+				return fmt.Errorf("unknown placeholder %s", placeholder)
+			}
+			rest = rest[open+end+1:]
+		}
+	}
+
+	if !strings.Contains(elements[len(elements)-1], "{name}") {
+		return fmt.Errorf("the last folder in the template must contain {name}")
+	}
+	return nil
+}
+
+// isSavePathPlaceholder reports whether p is one of SavePathPlaceholders
+func isSavePathPlaceholder(p string) bool {
+	for _, placeholder := range SavePathPlaceholders {
+		if placeholder.Name == p {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveSavePathTemplate returns tmpl if it is valid, otherwise the default template
+func EffectiveSavePathTemplate(tmpl string) string {
+	if err := ValidateSavePathTemplate(tmpl); err != nil {
+		log.Printf("Invalid save path template %q, using %q: %v", tmpl, DefaultSavePathTemplate, err)
+		return DefaultSavePathTemplate
+	}
+	return strings.TrimSpace(tmpl)
+}
+
+// sanitizePathElement makes a placeholder value safe to use as a single path element
+func sanitizePathElement(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < 32 {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.TrimSpace(s)
+	if s == "." || s == ".." {
+		return "_"
+	}
+	return s
+}
+
+// RenderSavePath expands a valid template into a relative path. Elements that end up
+// empty (such as {category} for an uncategorized torrent) are left out.
+func RenderSavePath(tmpl, name, category, hash string) string {
+	replacer := strings.NewReplacer(
+		"{name}", sanitizePathElement(name),
+		"{category}", sanitizePathElement(category),
+		"{hash}", hash,
+	)
+
+	elements := make([]string, 0)
+	for _, element := range splitTemplate(tmpl) {
+		rendered := strings.TrimSpace(replacer.Replace(element))
+		if rendered != "" {
+			elements = append(elements, rendered)
+		}
+	}
+	return filepath.Join(elements...)
+}
+
+// torrentSaveName picks the name used for {name}, falling back to the hash for unnamed torrents
+func torrentSaveName(info *metainfo.Info, infoHash metainfo.Hash) string {
+	if name := info.BestName(); name != metainfo.NoName && sanitizePathElement(name) != "" {
+		return name
+	}
+	return infoHash.HexString()
+}
+
+// newPieceCompletion opens the piece completion database shared by all torrent storage
+func newPieceCompletion(dataDir string) storage.PieceCompletion {
+	completion, err := storage.NewDefaultPieceCompletionForDir(dataDir)
+	if err != nil {
+		log.Printf("Error opening piece completion database, falling back to memory: %v", err)
+		return storage.NewMapPieceCompletion()
+	}
+	return completion
+}
+
+// newFileStorage creates file storage that keeps each torrent's data at the path returned by
+// contentPath. For single-file torrents that path is the file itself.
+func newFileStorage(baseDir string, completion storage.PieceCompletion, contentPath func(*metainfo.Info, metainfo.Hash) string) storage.ClientImpl {
+	return storage.NewFileOpts(storage.NewFileClientOpts{
+		ClientBaseDir: baseDir,
+		TorrentDirMaker: func(baseDir string, info *metainfo.Info, infoHash metainfo.Hash) string {
+			return contentPath(info, infoHash)
+		},
+		FilePathMaker: func(opts storage.FilePathMakerOpts) string {
+			return filepath.Join(opts.File.BestPath()...)
+		},
+		PieceCompletion: completion,
+	})
+}
+
+// TemplateSavePath returns where a torrent's data goes under baseDir according to the template
+func TemplateSavePath(baseDir, tmpl, category string, info *metainfo.Info, infoHash metainfo.Hash) string {
+	return filepath.Join(baseDir, RenderSavePath(tmpl, torrentSaveName(info, infoHash), category, infoHash.HexString()))
+}
+
+// newTorrentStorage creates file storage that places a torrent's data at baseDir/<rendered template>
+func newTorrentStorage(baseDir, tmpl, category string, completion storage.PieceCompletion) storage.ClientImpl {
+	return newFileStorage(baseDir, completion, func(info *metainfo.Info, infoHash metainfo.Hash) string {
+		return TemplateSavePath(baseDir, tmpl, category, info, infoHash)
+	})
+}
+
+// newSavedTorrentStorage creates file storage for a restored torrent whose save path is already known
+func newSavedTorrentStorage(savePath string, completion storage.PieceCompletion) storage.ClientImpl {
+	return newFileStorage(filepath.Dir(savePath), completion, func(*metainfo.Info, metainfo.Hash) string {
+		return savePath
+	})
+}
+
+// savePathsOverlap reports whether two save paths are the same, or one is inside the other,
+// so the torrents saved there would write over each other's data. Case is ignored for
+// file systems that do.
+func savePathsOverlap(a, b string) bool {
+	a, b = strings.ToLower(filepath.Clean(a)), strings.ToLower(filepath.Clean(b))
+	sep := string(filepath.Separator)
+	return a == b || strings.HasPrefix(a, b+sep) || strings.HasPrefix(b, a+sep)
+}
+
+// savePathOwners returns the torrents other than infoHash whose data overlaps path
+func savePathOwners(torrents map[string]*TorrentItem, path, infoHash string) []*TorrentItem {
+	owners := make([]*TorrentItem, 0)
+	for hash, item := range torrents {
+		if hash != infoHash && item.SavePath != "" && savePathsOverlap(item.SavePath, path) {
+			owners = append(owners, item)
+		}
+	}
+	return owners
+}
+
+// savePathReservations holds the save path of every torrent whose storage has been set up,
+// including torrents still waiting for their metadata that aren't in the list yet. It may
+// be used from any goroutine, as storage is opened on the client's.
+type savePathReservations struct {
+	mu    sync.Mutex
+	paths map[string]string // Save path by info-hash
+}
+
+// newSavePathReservations creates an empty set of reservations
+func newSavePathReservations() *savePathReservations {
+	return &savePathReservations{paths: make(map[string]string)}
+}
+
+// Reserve records path as where infoHash's data goes and returns it. A path overlapping
+// another torrent's is disambiguated first, unless share is set, such as for a cross-seed
+// that uses the other torrent's data on purpose. A torrent that already has a path keeps it.
+func (r *savePathReservations) Reserve(infoHash metainfo.Hash, path string, singleFile, share bool) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hash := infoHash.String()
+	if reserved, ok := r.paths[hash]; ok {
+		return reserved
+	}
+	if !share {
+		for other, reserved := range r.paths {
+			if other != hash && savePathsOverlap(reserved, path) {
+				path = disambiguateSavePath(path, infoHash, singleFile)
+				break
+			}
+		}
+	}
+	r.paths[hash] = path
+	return path
+}
+
+// Overlapping returns the paths, by info-hash, reserved for torrents other than infoHash
+// that overlap path, including torrents not in the list yet
+func (r *savePathReservations) Overlapping(path, infoHash string) map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	overlapping := make(map[string]string)
+	for hash, reserved := range r.paths {
+		if hash != infoHash && savePathsOverlap(reserved, path) {
+			overlapping[hash] = reserved
+		}
+	}
+	return overlapping
+}
+
+// Keep records path as where infoHash's data already is, such as for a restored torrent
+func (r *savePathReservations) Keep(infoHash string, path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paths[infoHash] = path
+}
+
+// Release frees the path of infoHash, such as once the torrent is removed or failed to add
+func (r *savePathReservations) Release(infoHash string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.paths, infoHash)
+}
+
+// disambiguateSavePath gives a save path that another torrent uses a name of its own by
+// adding the start of the info-hash, such as "Movie [1a2b3c4d]". Single files keep their
+// extension, becoming "Movie [1a2b3c4d].mkv".
+func disambiguateSavePath(path string, infoHash metainfo.Hash, singleFile bool) string {
+	suffix := " [" + infoHash.HexString()[:8] + "]"
+	dir, base := filepath.Split(path)
+	ext := ""
+	if singleFile {
+		ext = filepath.Ext(base)
+		base = strings.TrimSuffix(base, ext)
+	}
+	return filepath.Join(dir, base+suffix+ext)
+}
+
+// savePathWarning explains how item's data clashes with other torrents': kept in the same
+// place, such as by a cross-seed, or under the same name. reserved holds the overlapping
+// paths of torrents that may not be in torrents yet. It is empty when nothing clashes.
+func savePathWarning(item *TorrentItem, infoHash string, torrents map[string]*TorrentItem, reserved map[string]string) string {
+	if owners := savePathOwners(torrents, item.SavePath, infoHash); len(owners) > 0 {
+		return fmt.Sprintf("Data is in the same place as '%s'; deleting either keeps the files they share", owners[0].Name)
+	}
+	for hash := range reserved {
+		if _, listed := torrents[hash]; !listed {
+			return "Data is in the same place as a torrent still being added; deleting either keeps the files they share"
+		}
+	}
+	for hash, other := range torrents {
+		if hash != infoHash && strings.EqualFold(other.Name, item.Name) {
+			return fmt.Sprintf("Another torrent is also named '%s'; this one's data is at %s", item.Name, item.SavePath)
+		}
+	}
+	return ""
+}
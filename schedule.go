@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"golang.org/x/time/rate"
+)
+
+// SpeedSchedule marks, for each weekday (Sunday first) and hour, whether the alternative speed limits apply
+type SpeedSchedule [7][24]bool
+
+// ActiveAt reports whether the alternative limits are scheduled at t
+func (s *SpeedSchedule) ActiveAt(t time.Time) bool {
+	return s[t.Weekday()][t.Hour()]
+}
+
+// ScheduledHours returns how many hours per week use the alternative limits
+func (s *SpeedSchedule) ScheduledHours() int {
+	hours := 0
+	for _, day := range s {
+		for _, scheduled := range day {
+			if scheduled {
+				hours++
+			}
+		}
+	}
+	return hours
+}
+
+// ActiveSpeedLimits returns the download and upload limits in KiB/s that apply at t (0 meaning
+// unlimited), and whether they are the alternative limits
+func (c *Config) ActiveSpeedLimits(t time.Time) (download, upload int64, alternative bool) {
+	if c.AltSpeedEnabled || (c.ScheduleEnabled && c.Schedule.ActiveAt(t)) {
+		return c.AltDownloadLimit, c.AltUploadLimit, true
+	}
+	return c.DownloadLimit, c.UploadLimit, false
+}
+
+// applyRateLimit sets a limiter to limitKiB kibibytes per second, or unlimited when limitKiB is 0
+func applyRateLimit(limiter *rate.Limiter, limitKiB int64) {
+	limit := rate.Inf
+	if limitKiB > 0 {
+		limit = rate.Limit(limitKiB * 1024)
+	}
+	if limiter.Limit() != limit {
+		limiter.SetLimit(limit)
+	}
+}
+
+// FormatSpeedLimit converts a limit in KiB/s to a human-readable string
+func FormatSpeedLimit(limitKiB int64) string {
+	if limitKiB <= 0 {
+		return "Unlimited"
+	}
+	return HumanReadableRate(limitKiB * 1024)
+}
+
+// scheduleDayNames labels the grid rows, which start on Monday
+var scheduleDayNames = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// scheduleRowWeekday maps a grid row to its time.Weekday index
+func scheduleRowWeekday(row int) int {
+	return (row + 1) % 7
+}
+
+const (
+	scheduleLabelWidth  = 36
+	scheduleHeaderSize  = 18
+	scheduleMinCellSize = 14
+)
+
+// scheduleGrid is a 7×24 grid for editing a SpeedSchedule. Clicking a cell toggles it and
+// dragging paints every cell passed over with the same value.
+type scheduleGrid struct {
+	widget.BaseWidget
+
+	schedule   *SpeedSchedule
+	dragging   bool
+	paintValue bool
+
+	// OnChanged is called after the user edits the schedule
+	OnChanged func()
+}
+
+// newScheduleGrid creates a grid that edits schedule in place
+func newScheduleGrid(schedule *SpeedSchedule) *scheduleGrid {
+	g := &scheduleGrid{schedule: schedule}
+	g.ExtendBaseWidget(g)
+	return g
+}
+
+// cellAt returns the weekday and hour under pos
+func (g *scheduleGrid) cellAt(pos fyne.Position) (weekday, hour int, ok bool) {
+	size := g.Size()
+	cellWidth := (size.Width - scheduleLabelWidth) / 24
+	cellHeight := (size.Height - scheduleHeaderSize) / 7
+	if cellWidth <= 0 || cellHeight <= 0 || pos.X < scheduleLabelWidth || pos.Y < scheduleHeaderSize {
+		return 0, 0, false
+	}
+
+	column := int((pos.X - scheduleLabelWidth) / cellWidth)
+	row := int((pos.Y - scheduleHeaderSize) / cellHeight)
+	if column < 0 || column >= 24 || row < 0 || row >= 7 {
+		return 0, 0, false
+	}
+	return scheduleRowWeekday(row), column, true
+}
+
+// Tapped toggles the cell under the pointer
+func (g *scheduleGrid) Tapped(ev *fyne.PointEvent) {
+	weekday, hour, ok := g.cellAt(ev.Position)
+	if !ok {
+		return
+	}
+	g.schedule[weekday][hour] = !g.schedule[weekday][hour]
+	g.changed()
+}
+
+// Dragged paints cells with the opposite of the value of the cell where the drag started
+func (g *scheduleGrid) Dragged(ev *fyne.DragEvent) {
+	if !g.dragging {
+		start := ev.Position.Subtract(ev.Dragged)
+		weekday, hour, ok := g.cellAt(start)
+		if !ok {
+			return
+		}
+		g.dragging = true
+		g.paintValue = !g.schedule[weekday][hour]
+		g.schedule[weekday][hour] = g.paintValue
+	}
+
+	weekday, hour, ok := g.cellAt(ev.Position)
+	if !ok || g.schedule[weekday][hour] == g.paintValue {
+		return
+	}
+	g.schedule[weekday][hour] = g.paintValue
+	g.changed()
+}
+
+// DragEnd finishes painting
+func (g *scheduleGrid) DragEnd() {
+	if g.dragging {
+		g.dragging = false
+		g.changed()
+	}
+}
+
+func (g *scheduleGrid) changed() {
+	g.Refresh()
+	if g.OnChanged != nil {
+		g.OnChanged()
+	}
+}
+
+// CreateRenderer builds the cells and labels of the grid
+func (g *scheduleGrid) CreateRenderer() fyne.WidgetRenderer {
+	r := &scheduleGridRenderer{grid: g}
+
+	for row := 0; row < 7; row++ {
+		for hour := 0; hour < 24; hour++ {
+			r.cells[row][hour] = canvas.NewRectangle(color.Transparent)
+			r.objects = append(r.objects, r.cells[row][hour])
+		}
+
+		label := canvas.NewText(scheduleDayNames[row], theme.Color(theme.ColorNameForeground))
+		label.TextSize = theme.CaptionTextSize()
+		r.dayLabels = append(r.dayLabels, label)
+		r.objects = append(r.objects, label)
+	}
+
+	for hour := 0; hour < 24; hour += 3 {
+		label := canvas.NewText(fmt.Sprintf("%02d", hour), theme.Color(theme.ColorNameForeground))
+		label.TextSize = theme.CaptionTextSize()
+		r.hourLabels = append(r.hourLabels, label)
+		r.objects = append(r.objects, label)
+	}
+
+	// Outline around the current hour
+	r.nowMarker = canvas.NewRectangle(color.Transparent)
+	r.nowMarker.StrokeWidth = 2
+	r.objects = append(r.objects, r.nowMarker)
+
+	r.Refresh()
+	return r
+}
+
+type scheduleGridRenderer struct {
+	grid       *scheduleGrid
+	cells      [7][24]*canvas.Rectangle
+	dayLabels  []*canvas.Text
+	hourLabels []*canvas.Text
+	nowMarker  *canvas.Rectangle
+	objects    []fyne.CanvasObject
+}
+
+func (r *scheduleGridRenderer) cellSize(size fyne.Size) fyne.Size {
+	return fyne.NewSize((size.Width-scheduleLabelWidth)/24, (size.Height-scheduleHeaderSize)/7)
+}
+
+func (r *scheduleGridRenderer) Layout(size fyne.Size) {
+	cell := r.cellSize(size)
+	gap := float32(1)
+
+	for row := 0; row < 7; row++ {
+		y := scheduleHeaderSize + float32(row)*cell.Height
+		for hour := 0; hour < 24; hour++ {
+			rect := r.cells[row][hour]
+			rect.Move(fyne.NewPos(scheduleLabelWidth+float32(hour)*cell.Width, y))
+			rect.Resize(fyne.NewSize(cell.Width-gap, cell.Height-gap))
+		}
+		r.dayLabels[row].Move(fyne.NewPos(0, y+(cell.Height-r.dayLabels[row].MinSize().Height)/2))
+	}
+
+	for i, label := range r.hourLabels {
+		label.Move(fyne.NewPos(scheduleLabelWidth+float32(i*3)*cell.Width, 0))
+	}
+
+	now := time.Now()
+	row := (int(now.Weekday()) + 6) % 7
+	r.nowMarker.Move(fyne.NewPos(scheduleLabelWidth+float32(now.Hour())*cell.Width,
+		scheduleHeaderSize+float32(row)*cell.Height))
+	r.nowMarker.Resize(fyne.NewSize(cell.Width-gap, cell.Height-gap))
+}
+
+func (r *scheduleGridRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(scheduleLabelWidth+24*scheduleMinCellSize, scheduleHeaderSize+7*scheduleMinCellSize)
+}
+
+func (r *scheduleGridRenderer) Refresh() {
+	scheduledColor := theme.Color(theme.ColorNamePrimary)
+	unscheduledColor := theme.Color(theme.ColorNameInputBackground)
+
+	for row := 0; row < 7; row++ {
+		weekday := scheduleRowWeekday(row)
+		for hour := 0; hour < 24; hour++ {
+			if r.grid.schedule[weekday][hour] {
+				r.cells[row][hour].FillColor = scheduledColor
+			} else {
+				r.cells[row][hour].FillColor = unscheduledColor
+			}
+			r.cells[row][hour].Refresh()
+		}
+	}
+
+	// Labels follow the theme, which may have changed since they were created
+	for _, label := range append(append([]*canvas.Text(nil), r.dayLabels...), r.hourLabels...) {
+		label.Color = theme.Color(theme.ColorNameForeground)
+		label.Refresh()
+	}
+
+	r.nowMarker.StrokeColor = theme.Color(theme.ColorNameForeground)
+	r.Layout(r.grid.Size())
+	canvas.Refresh(r.grid)
+}
+
+func (r *scheduleGridRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *scheduleGridRenderer) Destroy() {}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// seedingGoalReached reports whether a completed torrent has seeded for as long as the
+// seeding goal asks, which is straight away when there is no goal
+func seedingGoalReached(item *TorrentItem, config *Config, now time.Time) bool {
+	if item.Progress < 1.0 || item.CompletedAt.IsZero() {
+		return false
+	}
+	goal := time.Duration(config.SeedingGoalHours) * time.Hour
+	return now.Sub(item.CompletedAt) >= goal
+}
+
+// autoRemoveDue reports whether a torrent should be removed automatically: auto-removal
+// is on, and the grace period has passed since it reached its seeding goal
+func autoRemoveDue(item *TorrentItem, config *Config, now time.Time) bool {
+	if !config.AutoRemove || item.Checking || item.MetadataOnly {
+		return false
+	}
+	grace := time.Duration(config.AutoRemoveGrace) * time.Minute
+	return seedingGoalReached(item, config, now.Add(-grace))
+}
+
+// validateSeedingGoal accepts a whole number of hours, 0 meaning as soon as complete
+func validateSeedingGoal(text string) error {
+	hours, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || hours < 0 {
+		return fmt.Errorf("enter a whole number of hours (0 for as soon as complete)")
+	}
+	return nil
+}
+
+// parseSeedingGoal reads a validated seeding goal
+func parseSeedingGoal(text string) int {
+	hours, _ := strconv.Atoi(strings.TrimSpace(text))
+	return hours
+}
+
+// validateGracePeriod accepts a whole number of minutes, 0 meaning no delay
+func validateGracePeriod(text string) error {
+	minutes, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || minutes < 0 {
+		return fmt.Errorf("enter a whole number of minutes (0 for no delay)")
+	}
+	return nil
+}
+
+// parseGracePeriod reads a validated grace period
+func parseGracePeriod(text string) int {
+	minutes, _ := strconv.Atoi(strings.TrimSpace(text))
+	return minutes
+}
@@ -0,0 +1,16 @@
+package main
+
+// selectionRow finds the row that shows the selected torrent, hash, among hashes in the
+// order the list shows them. It is -1 when nothing is selected or the torrent is gone,
+// meaning the selection should be cleared.
+func selectionRow(hash string, hashes []string) int {
+	if hash == "" {
+		return -1
+	}
+	for i, h := range hashes {
+		if h == hash {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestSelectionRow(t *testing.T) {
+	tests := []struct {
+		name     string
+		selected string
+		hashes   []string
+		want     int
+	}{
+		{"nothing selected", "", []string{"a", "b"}, -1},
+		{"selected row removed", "b", []string{"a", "c"}, -1},
+		{"last torrent removed", "a", nil, -1},
+		{"row above removed", "c", []string{"b", "c"}, 1},
+		{"row below removed", "a", []string{"a", "b"}, 0},
+		{"rows reordered", "a", []string{"c", "b", "a"}, 2},
+		{"unchanged", "b", []string{"a", "b", "c"}, 1},
+	}
+	for _, tt := range tests {
+		if got := selectionRow(tt.selected, tt.hashes); got != tt.want {
+			t.Errorf("%s: selectionRow(%q, %v) = %d, want %d", tt.name, tt.selected, tt.hashes, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// sessionVersion is written to new session files
+const sessionVersion = 1
+
+// StartupPolicy is how torrents restored on launch are started
+type StartupPolicy string
+
+const (
+	StartupRestore StartupPolicy = "restore" // Each torrent paused or running as it was left
+	StartupResume  StartupPolicy = "resume"  // Every torrent runs, including ones left paused
+	StartupPaused  StartupPolicy = "paused"  // Every torrent starts paused
+)
+
+// StartupPolicyNames lists the policies in the order they are offered in the UI
+var StartupPolicyNames = []string{"Restore previous state per torrent", "Resume active torrents", "Start everything paused"}
+
+// String returns the name shown in the UI
+func (p StartupPolicy) String() string {
+	switch p {
+	case StartupResume:
+		return "Resume active torrents"
+	case StartupPaused:
+		return "Start everything paused"
+	}
+	return "Restore previous state per torrent"
+}
+
+// ParseStartupPolicy converts a UI name back into a StartupPolicy
+func ParseStartupPolicy(name string) StartupPolicy {
+	switch name {
+	case "Resume active torrents":
+		return StartupResume
+	case "Start everything paused":
+		return StartupPaused
+	}
+	return StartupRestore
+}
+
+// pausedOnLaunch reports whether a restored torrent starts paused, given whether it was
+// paused when the last session ended
+func (p StartupPolicy) pausedOnLaunch(wasPaused bool) bool {
+	switch p {
+	case StartupResume:
+		return false
+	case StartupPaused:
+		return true
+	}
+	return wasPaused
+}
+
+// Session is the saved list of torrents restored on the next launch
+type Session struct {
+	Version  int              `json:"version"`
+	Torrents []SessionTorrent `json:"torrents"`
+
+	// Data sent and received by every torrent ever added, for the all-time share ratio
+	Transferred TransferTotals `json:"transferred,omitzero"`
+
+	// Torrents whose metadata hadn't arrived yet, added again on the next launch
+	Pending []PendingTorrent `json:"pending,omitempty"`
+}
+
+// PendingTorrent is a torrent added without its metadata, such as from a magnet link, that
+// hadn't received it when the session was saved. It has no metainfo file yet, so it is
+// added again from its info-hash and trackers.
+type PendingTorrent struct {
+	InfoHash string     `json:"info_hash"`
+	Name     string     `json:"name,omitempty"`
+	Trackers [][]string `json:"trackers,omitempty"`
+	AddedAt  time.Time  `json:"added_at"`
+
+	SaveDir      string `json:"save_dir,omitempty"`      // Base directory for the save path template
+	Category     string `json:"category,omitempty"`      // Category used for the {category} placeholder
+	MetadataOnly bool   `json:"metadata_only,omitempty"` // Stop once the metadata arrives
+	Preallocate  bool   `json:"preallocate,omitempty"`   // Reserve the files' full size when starting
+	Paused       bool   `json:"paused,omitempty"`        // Added without starting transfers
+	VerifyHash   bool   `json:"verify_hash,omitempty"`   // Check the metadata against the info-hash
+}
+
+// newPendingTorrent records a torrent added from spec with opts until its metadata arrives
+func newPendingTorrent(spec *torrent.TorrentSpec, opts AddOptions) PendingTorrent {
+	return PendingTorrent{
+		InfoHash: spec.InfoHash.String(),
+		Name:     spec.DisplayName,
+		Trackers: spec.Trackers,
+		AddedAt:  time.Now(),
+
+		SaveDir:      opts.SaveDir,
+		Category:     opts.Category,
+		MetadataOnly: opts.MetadataOnly,
+		Preallocate:  opts.Preallocate,
+		Paused:       opts.Paused,
+		VerifyHash:   opts.MagnetHash != (metainfo.Hash{}),
+	}
+}
+
+// Spec returns what to add the torrent from again, with the options it was added with
+func (p PendingTorrent) Spec() (*torrent.TorrentSpec, AddOptions, error) {
+	var hash metainfo.Hash
+	if err := hash.FromHexString(p.InfoHash); err != nil {
+		return nil, AddOptions{}, fmt.Errorf("invalid info-hash %q: %v", p.InfoHash, err)
+	}
+	spec := &torrent.TorrentSpec{InfoHash: hash, DisplayName: p.Name, Trackers: p.Trackers}
+	opts := AddOptions{
+		Category:     p.Category,
+		SaveDir:      p.SaveDir,
+		MetadataOnly: p.MetadataOnly,
+		Preallocate:  p.Preallocate,
+		Paused:       p.Paused,
+	}
+	if p.VerifyHash {
+		opts.MagnetHash = hash
+	}
+	return spec, opts, nil
+}
+
+// SessionTorrent is the saved state of one torrent. Its metainfo is kept separately in
+// the torrents directory, named after the info-hash.
+type SessionTorrent struct {
+	InfoHash string              `json:"info_hash"`
+	Name     string              `json:"name"`
+	Category string              `json:"category,omitempty"`
+	SavePath string              `json:"save_path"`
+	AddedAt  time.Time           `json:"added_at"`
+	Files    []sessionFileRecord `json:"files,omitempty"`
+
+	MetadataOnly  bool            `json:"metadata_only,omitempty"` // Still waiting for the user to start downloading
+	Trackers      [][]string      `json:"trackers,omitempty"`      // Announce list, replacing the one in the metainfo
+	UploadSlots   int             `json:"upload_slots,omitempty"`  // Upload slot limit of its own
+	Priority      TorrentPriority `json:"priority,omitempty"`      // Preference over the other torrents
+	QueuePosition int             `json:"queue_position"`          // Place in the manual queue order
+	CompletedAt   time.Time       `json:"completed_at,omitzero"`   // When the download finished, for the seeding goal
+	Note          string          `json:"note,omitempty"`          // Free-text note from the user
+	Preallocate   bool            `json:"preallocate,omitempty"`   // Reserve the files' full size when starting
+	Paused        bool            `json:"paused,omitempty"`        // Paused by the user
+	Transferred   TransferTotals  `json:"transferred,omitzero"`    // Data sent and received, for its share ratio
+	AutoPaused    string          `json:"auto_paused,omitempty"`   // Why it was paused after repeated errors
+	MaxSeeds      int             `json:"max_seeds,omitempty"`     // Seed connections kept at once
+	PreferSeeds   bool            `json:"prefer_seeds,omitempty"`  // Make room for seeds near completion
+}
+
+// sessionFileRecord is how a FileInfo is stored. The pointer fields distinguish values
+// missing from older session files from deliberate choices.
+type sessionFileRecord struct {
+	Path     string        `json:"path"`
+	Selected *bool         `json:"selected,omitempty"`
+	Priority *FilePriority `json:"priority,omitempty"`
+	Alias    string        `json:"alias,omitempty"`
+}
+
+// newSessionTorrent records the state of a torrent in the list
+func newSessionTorrent(infoHash string, item *TorrentItem) SessionTorrent {
+	return SessionTorrent{
+		InfoHash: infoHash,
+		Name:     item.Name,
+		Category: item.Category,
+		SavePath: item.SavePath,
+		AddedAt:  item.AddedAt,
+		Files:    newSessionFileRecords(item.Files),
+
+		MetadataOnly:  item.MetadataOnly,
+		Trackers:      item.Trackers,
+		UploadSlots:   item.UploadSlots,
+		Priority:      item.Priority,
+		QueuePosition: item.QueuePosition,
+		CompletedAt:   item.CompletedAt,
+		Note:          item.Note,
+		Preallocate:   item.Preallocate,
+		Paused:        item.Paused,
+		Transferred:   item.Transferred,
+		AutoPaused:    item.AutoPaused,
+		MaxSeeds:      item.MaxSeeds,
+		PreferSeeds:   item.PreferSeeds,
+	}
+}
+
+// newSessionFileRecords converts file infos into their stored form
+func newSessionFileRecords(files []FileInfo) []sessionFileRecord {
+	records := make([]sessionFileRecord, 0, len(files))
+	for _, f := range files {
+		selected := f.Selected
+		priority := f.Priority
+		records = append(records, sessionFileRecord{
+			Path:     f.Path,
+			Selected: &selected,
+			Priority: &priority,
+			Alias:    f.Alias,
+		})
+	}
+	return records
+}
+
+// FileInfos converts stored file records back into file infos. Files saved before
+// selections existed are treated as selected at normal priority.
+func (st SessionTorrent) FileInfos() []FileInfo {
+	files := make([]FileInfo, 0, len(st.Files))
+	for _, record := range st.Files {
+		f := FileInfo{
+			Path:     record.Path,
+			Selected: true,
+			Priority: FilePriorityNormal,
+			Alias:    record.Alias,
+		}
+		if record.Selected != nil {
+			f.Selected = *record.Selected
+		}
+		if record.Priority != nil && *record.Priority != "" {
+			f.Priority = *record.Priority
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// SessionPath returns the path of the session file
+func SessionPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "session.json"), nil
+}
+
+// TorrentFilesDir returns the directory holding the metainfo of saved torrents
+func TorrentFilesDir() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "torrents"), nil
+}
+
+// torrentFilePath returns where the metainfo for an info-hash is kept
+func torrentFilePath(infoHash string) (string, error) {
+	dir, err := TorrentFilesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, infoHash+".torrent"), nil
+}
+
+// LoadSession reads the session file, returning an empty session if it doesn't exist yet.
+// A file that can't be parsed is moved aside to session.json.bad, so the next save doesn't
+// write over the torrents in it.
+func LoadSession() (*Session, error) {
+	session := &Session{Version: sessionVersion}
+
+	path, err := SessionPath()
+	if err != nil {
+		return session, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return session, nil
+	}
+	if err != nil {
+		return session, err
+	}
+
+	if err := json.Unmarshal(data, session); err != nil {
+		badPath := path + ".bad"
+		if renameErr := os.Rename(path, badPath); renameErr != nil {
+			return &Session{Version: sessionVersion}, fmt.Errorf("error parsing %s, left in place: %v", path, err)
+		}
+		return &Session{Version: sessionVersion}, fmt.Errorf("error parsing %s, moved to %s: %v", path, badPath, err)
+	}
+	session.Version = sessionVersion
+	return session, nil
+}
+
+// Save writes the session file, replacing it atomically
+func (s *Session) Save() error {
+	path, err := SessionPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// SaveTorrentFile stores a torrent's metainfo so it can be re-added on the next launch
+func SaveTorrentFile(t *torrent.Torrent) error {
+	path, err := torrentFilePath(t.InfoHash().String())
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	mi := t.Metainfo()
+	if err := mi.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// RemoveTorrentFile deletes the stored metainfo of a removed torrent
+func RemoveTorrentFile(infoHash string) error {
+	path, err := torrentFilePath(infoHash)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,570 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// parseCategories turns the one-per-line categories text into a de-duplicated list
+func parseCategories(text string) []string {
+	categories := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(text, "\n") {
+		category := strings.TrimSpace(line)
+		if category == "" || seen[category] {
+			continue
+		}
+		seen[category] = true
+		categories = append(categories, category)
+	}
+	return categories
+}
+
+// validateSpeedLimit accepts a whole number of KiB/s, 0 meaning unlimited
+func validateSpeedLimit(text string) error {
+	limit, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+	if err != nil || limit < 0 {
+		return fmt.Errorf("enter a whole number of KiB/s (0 for unlimited)")
+	}
+	return nil
+}
+
+// newSpeedLimitEntry creates an entry for a speed limit in KiB/s
+func newSpeedLimitEntry(limitKiB int64) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("0 = unlimited")
+	entry.SetText(strconv.FormatInt(limitKiB, 10))
+	entry.Validator = validateSpeedLimit
+	return entry
+}
+
+// parseSpeedLimit reads a validated speed limit entry
+func parseSpeedLimit(entry *widget.Entry) int64 {
+	limit, _ := strconv.ParseInt(strings.TrimSpace(entry.Text), 10, 64)
+	return limit
+}
+
+// showSettingsDialog lets the user edit the settings and saves them when confirmed
+func showSettingsDialog(w fyne.Window, config *Config, dataDir string, onSaved func()) {
+	// Entries whose validation gates the Save button
+	validated := make([]*widget.Entry, 0)
+
+	// General settings
+
+	categoriesInput := widget.NewMultiLineEntry()
+	categoriesInput.SetPlaceHolder("One category per line")
+	categoriesInput.SetText(strings.Join(config.Categories, "\n"))
+
+	// Preview of where an example torrent would be saved
+	previewLabel := widget.NewLabel("")
+	updatePreview := func(tmpl string) {
+		if err := ValidateSavePathTemplate(tmpl); err != nil {
+			previewLabel.SetText("-")
+			return
+		}
+		category := "Movies"
+		if categories := parseCategories(categoriesInput.Text); len(categories) > 0 {
+			category = categories[0]
+		}
+		previewLabel.SetText(filepath.Join(dataDir, RenderSavePath(tmpl, "Example Torrent", category, strings.Repeat("0", 40))))
+	}
+
+	templateInput := widget.NewEntry()
+	templateInput.SetPlaceHolder(DefaultSavePathTemplate)
+	templateInput.SetText(config.SavePathTemplate)
+	templateInput.Validator = ValidateSavePathTemplate
+	templateInput.OnChanged = updatePreview
+	categoriesInput.OnChanged = func(string) {
+		updatePreview(templateInput.Text)
+	}
+	updatePreview(templateInput.Text)
+	validated = append(validated, templateInput)
+
+	// Describe the available placeholders below the template field
+	placeholderHelp := make([]string, 0, len(SavePathPlaceholders))
+	for _, placeholder := range SavePathPlaceholders {
+		placeholderHelp = append(placeholderHelp, fmt.Sprintf("%s: %s", placeholder.Name, placeholder.Description))
+	}
+	templateItem := widget.NewFormItem("Save Path Template", templateInput)
+	templateItem.HintText = strings.Join(placeholderHelp, ", ")
+
+	checkExistingInput := widget.NewCheck("Check existing files when adding torrents", nil)
+	checkExistingInput.SetChecked(config.CheckExistingFiles)
+
+	preallocateInput := widget.NewCheck("Preallocate disk space for new torrents", nil)
+	preallocateInput.SetChecked(config.Preallocate)
+	preallocateItem := widget.NewFormItem("", preallocateInput)
+	preallocateItem.HintText = "Reserves each file's full size up front, which reduces fragmentation and finds a full disk early"
+
+	startupPolicyInput := widget.NewSelect(StartupPolicyNames, nil)
+	startupPolicyInput.SetSelected(config.StartupPolicy.String())
+	startupPolicyItem := widget.NewFormItem("On Launch", startupPolicyInput)
+	startupPolicyItem.HintText = "How torrents from the last session start, to keep bandwidth free on boot"
+
+	batchErrorInput := widget.NewEntry()
+	batchErrorInput.SetPlaceHolder("0 = never stop")
+	batchErrorInput.SetText(strconv.Itoa(config.BatchErrorLimit))
+	batchErrorInput.Validator = validateErrorStreak
+	validated = append(validated, batchErrorInput)
+	batchErrorItem := widget.NewFormItem("Stop Batch After", batchErrorInput)
+	batchErrorItem.HintText = "Failed adds in a row that stop a batch add or an import, reporting one error instead of many"
+
+	errorPauseInput := widget.NewEntry()
+	errorPauseInput.SetPlaceHolder("0 = never pause")
+	errorPauseInput.SetText(strconv.Itoa(config.ErrorPauseLimit))
+	errorPauseInput.Validator = validateErrorPauseLimit
+	validated = append(validated, errorPauseInput)
+	errorPauseItem := widget.NewFormItem("Pause After Errors", errorPauseInput)
+	errorPauseItem.HintText = "Recent errors, such as failed disk writes, that pause a torrent. Older errors count for less."
+
+	urlTimeoutInput := widget.NewEntry()
+	urlTimeoutInput.SetText(strconv.Itoa(config.TorrentURLTimeout))
+	urlTimeoutInput.Validator = validateTorrentURLTimeout
+	urlMaxSizeInput := widget.NewEntry()
+	urlMaxSizeInput.SetText(strconv.Itoa(config.TorrentURLMaxSize))
+	urlMaxSizeInput.Validator = validateTorrentURLMaxSize
+	validated = append(validated, urlTimeoutInput, urlMaxSizeInput)
+	urlTimeoutItem := widget.NewFormItem("URL Timeout (seconds)", urlTimeoutInput)
+	urlTimeoutItem.HintText = fmt.Sprintf("Per attempt when adding a .torrent URL, tried up to %d times if the server is unreachable or busy",
+		torrentURLAttempts)
+	urlMaxSizeItem := widget.NewFormItem("URL Size Limit (MiB)", urlMaxSizeInput)
+	urlMaxSizeItem.HintText = "Larger downloads are stopped, as no real .torrent file is that big"
+
+	logLevelInput := widget.NewSelect(LogLevelNames, nil)
+	logLevelInput.SetSelected(config.LogLevel.String())
+	logLevelItem := widget.NewFormItem("Log Level", logLevelInput)
+	logLevelItem.HintText = "Least severe messages shown in the Log tab, from Reed and the torrent engine. Applied on launch."
+
+	resetColumnsInput := widget.NewCheck("Reset the Files and Peers columns to their default widths", nil)
+	resetColumnsItem := widget.NewFormItem("Columns", resetColumnsInput)
+	resetColumnsItem.HintText = "Drag the dividers in a list's header to resize its columns; widths are kept between launches"
+
+	swarmTotalsInput := widget.NewCheck("Show peer and seed totals in the status bar", nil)
+	swarmTotalsInput.SetChecked(config.ShowSwarmTotals)
+	speedGraphInput := widget.NewCheck("Show a graph of the last minute's download speed in the status bar", nil)
+	speedGraphInput.SetChecked(config.ShowSpeedGraph)
+
+	keepActivityInput := widget.NewCheck("Keep the activity feed between launches", nil)
+	keepActivityInput.SetChecked(config.KeepActivity)
+
+	commandPaletteInput := widget.NewCheck("Open the command palette with Ctrl+K", nil)
+	commandPaletteInput.SetChecked(config.CommandPalette)
+
+	watchClipboardInput := widget.NewCheck("Offer to add magnet links and .torrent URLs copied to the clipboard", nil)
+	watchClipboardInput.SetChecked(config.WatchClipboard)
+
+	verifyMagnetsInput := widget.NewCheck("Check that metadata matches the info-hash of its magnet link", nil)
+	verifyMagnetsInput.SetChecked(config.VerifyMagnets)
+
+	// The webhook URL is only required, and checked, while the webhook is enabled
+	webhookURLInput := widget.NewEntry()
+	webhookURLInput.SetPlaceHolder("https://example.com/hooks/reed")
+	webhookURLInput.SetText(config.WebhookURL)
+	webhookEnabledInput := widget.NewCheck("POST a JSON message to a URL when a torrent completes", func(enabled bool) {
+		if enabled {
+			webhookURLInput.Enable()
+		} else {
+			webhookURLInput.Disable()
+		}
+		webhookURLInput.Validate()
+	})
+	webhookURLInput.Validator = func(text string) error {
+		if !webhookEnabledInput.Checked {
+			return nil
+		}
+		return validateWebhookURL(text)
+	}
+	validated = append(validated, webhookURLInput)
+	webhookEnabledInput.SetChecked(config.WebhookEnabled)
+	if !config.WebhookEnabled {
+		webhookURLInput.Disable()
+	}
+	webhookURLItem := widget.NewFormItem("Webhook URL", webhookURLInput)
+	webhookURLItem.HintText = "Receives the name, info-hash, size and save path, with up to 3 attempts"
+
+	confirmRemoveInput := widget.NewCheck("Ask for confirmation before removing torrents", nil)
+	confirmRemoveInput.SetChecked(config.ConfirmRemove)
+	confirmRemoveItem := widget.NewFormItem("", confirmRemoveInput)
+	confirmRemoveItem.HintText = "When off, removing keeps the downloaded files. Right-click a torrent to remove it with its files."
+
+	// Magnet link handler registration takes effect immediately rather than on Save
+	registerButton := widget.NewButton("Make Reed the Default Magnet Handler", func() {
+		if err := registerMagnetHandler(); err != nil {
+			dialog.ShowError(fmt.Errorf("error registering magnet handler: %v", err), w)
+			return
+		}
+		dialog.ShowInformation("Magnet Links", "Reed will now open magnet links.", w)
+	})
+	unregisterButton := widget.NewButton("Unregister", func() {
+		if err := unregisterMagnetHandler(); err != nil {
+			dialog.ShowError(fmt.Errorf("error unregistering magnet handler: %v", err), w)
+			return
+		}
+		dialog.ShowInformation("Magnet Links", "Reed is no longer registered for magnet links.", w)
+	})
+
+	generalForm := widget.NewForm(
+		widget.NewFormItem("Categories", categoriesInput),
+		templateItem,
+		widget.NewFormItem("Example", previewLabel),
+		widget.NewFormItem("", checkExistingInput),
+		preallocateItem,
+		startupPolicyItem,
+		batchErrorItem,
+		errorPauseItem,
+		urlTimeoutItem,
+		urlMaxSizeItem,
+		logLevelItem,
+		resetColumnsItem,
+		widget.NewFormItem("", swarmTotalsInput),
+		widget.NewFormItem("", speedGraphInput),
+		confirmRemoveItem,
+		widget.NewFormItem("", keepActivityInput),
+		widget.NewFormItem("", commandPaletteInput),
+		widget.NewFormItem("", watchClipboardInput),
+		widget.NewFormItem("", verifyMagnetsInput),
+		widget.NewFormItem("", webhookEnabledInput),
+		webhookURLItem,
+		widget.NewFormItem("Magnet Links", container.NewHBox(registerButton, unregisterButton)),
+		widget.NewFormItem("Storage", widget.NewButtonWithIcon("Show Storage Locations", theme.FolderIcon(), func() {
+			showLocationsDialog(w, dataDir)
+		})),
+	)
+
+	// Speed settings
+
+	downloadLimitInput := newSpeedLimitEntry(config.DownloadLimit)
+	uploadLimitInput := newSpeedLimitEntry(config.UploadLimit)
+	altDownloadLimitInput := newSpeedLimitEntry(config.AltDownloadLimit)
+	altUploadLimitInput := newSpeedLimitEntry(config.AltUploadLimit)
+	validated = append(validated, downloadLimitInput, uploadLimitInput, altDownloadLimitInput, altUploadLimitInput)
+
+	uploadSlotsInput := widget.NewEntry()
+	uploadSlotsInput.SetPlaceHolder("0 = unlimited")
+	uploadSlotsInput.SetText(strconv.Itoa(config.UploadSlots))
+	uploadSlotsInput.Validator = validateUploadSlots
+	validated = append(validated, uploadSlotsInput)
+	uploadSlotsItem := widget.NewFormItem("Upload Slots per Torrent", uploadSlotsInput)
+	uploadSlotsItem.HintText = "Complete torrents upload to one peer per connection, so this caps their connections"
+
+	maxConnectionsInput := widget.NewEntry()
+	maxConnectionsInput.SetPlaceHolder("0 = unlimited")
+	maxConnectionsInput.SetText(strconv.Itoa(config.MaxConnections))
+	maxConnectionsInput.Validator = validateMaxConnections
+	validated = append(validated, maxConnectionsInput)
+	maxConnectionsItem := widget.NewFormItem("Maximum Connections", maxConnectionsInput)
+	maxConnectionsItem.HintText = "Peer connections across all torrents, shared evenly between them"
+
+	activeDownloadsInput := widget.NewEntry()
+	activeDownloadsInput.SetPlaceHolder("0 = unlimited")
+	activeDownloadsInput.SetText(strconv.Itoa(config.MaxActiveDownloads))
+	activeDownloadsInput.Validator = validateActiveDownloads
+	validated = append(validated, activeDownloadsInput)
+	activeDownloadsItem := widget.NewFormItem("Active Downloads", activeDownloadsInput)
+	activeDownloadsItem.HintText = "Torrents beyond this wait in the queue, in the order shown in the Queue tab"
+
+	// Battery status can't be read everywhere, so the option only works where it can
+	batteryThresholdInput := widget.NewEntry()
+	batteryThresholdInput.SetText(strconv.Itoa(config.BatteryThreshold))
+	batteryThresholdInput.Validator = validateBatteryThreshold
+	validated = append(validated, batteryThresholdInput)
+	pauseOnBatteryInput := widget.NewCheck("Pause all torrents on battery power below", nil)
+	pauseOnBatteryInput.SetChecked(config.PauseOnBattery)
+	batteryItem := widget.NewFormItem("Battery (%)", container.NewBorder(nil, nil, pauseOnBatteryInput, nil, batteryThresholdInput))
+	batteryItem.HintText = "Torrents resume once plugged in"
+	if !batterySupported {
+		pauseOnBatteryInput.Disable()
+		batteryThresholdInput.Disable()
+		batteryItem.HintText = "Battery status isn't available on this platform"
+	}
+
+	altSpeedInput := widget.NewCheck("Always use alternative limits", nil)
+	altSpeedInput.SetChecked(config.AltSpeedEnabled)
+	scheduleEnabledInput := widget.NewCheck("Use alternative limits during scheduled hours", nil)
+	scheduleEnabledInput.SetChecked(config.ScheduleEnabled)
+
+	// Edit a copy of the schedule so Cancel discards changes
+	schedule := config.Schedule
+	scheduleGrid := newScheduleGrid(&schedule)
+	scheduleSummary := widget.NewLabel("")
+	updateScheduleSummary := func() {
+		state := "not active now"
+		if schedule.ActiveAt(time.Now()) {
+			state = "active now"
+		}
+		scheduleSummary.SetText(fmt.Sprintf("Alternative limits scheduled for %d of 168 hours per week (%s)",
+			schedule.ScheduledHours(), state))
+	}
+	scheduleGrid.OnChanged = updateScheduleSummary
+	updateScheduleSummary()
+
+	speedContent := container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem("Download Limit (KiB/s)", downloadLimitInput),
+			widget.NewFormItem("Upload Limit (KiB/s)", uploadLimitInput),
+			widget.NewFormItem("Alternative Download (KiB/s)", altDownloadLimitInput),
+			widget.NewFormItem("Alternative Upload (KiB/s)", altUploadLimitInput),
+			uploadSlotsItem,
+			maxConnectionsItem,
+			activeDownloadsItem,
+			batteryItem,
+		),
+		altSpeedInput,
+		scheduleEnabledInput,
+		widget.NewLabel("Click or drag across hours to schedule the alternative limits:"),
+		scheduleGrid,
+		scheduleSummary,
+		container.NewHBox(
+			widget.NewButton("Clear Schedule", func() {
+				schedule = SpeedSchedule{}
+				scheduleGrid.Refresh()
+				updateScheduleSummary()
+			}),
+		),
+	)
+
+	// Seeding settings
+
+	seedingGoalInput := widget.NewEntry()
+	seedingGoalInput.SetPlaceHolder("0 = as soon as complete")
+	seedingGoalInput.SetText(strconv.Itoa(config.SeedingGoalHours))
+	seedingGoalInput.Validator = validateSeedingGoal
+	validated = append(validated, seedingGoalInput)
+	seedingGoalItem := widget.NewFormItem("Seeding Goal (hours)", seedingGoalInput)
+	seedingGoalItem.HintText = "How long a completed torrent seeds before it counts as done"
+
+	autoRemoveInput := widget.NewCheck("Remove torrents from the list once they reach the seeding goal", nil)
+	autoRemoveInput.SetChecked(config.AutoRemove)
+	autoRemoveItem := widget.NewFormItem("", autoRemoveInput)
+	autoRemoveItem.HintText = "Downloaded files are always kept"
+
+	graceInput := widget.NewEntry()
+	graceInput.SetPlaceHolder("0 = no delay")
+	graceInput.SetText(strconv.Itoa(config.AutoRemoveGrace))
+	graceInput.Validator = validateGracePeriod
+	validated = append(validated, graceInput)
+	graceItem := widget.NewFormItem("Grace Period (minutes)", graceInput)
+	graceItem.HintText = "Extra time after the seeding goal before a torrent is removed"
+
+	minRatioInput := widget.NewEntry()
+	minRatioInput.SetPlaceHolder("0 = no minimum")
+	minRatioInput.SetText(strconv.FormatFloat(config.MinRatio, 'f', -1, 64))
+	minRatioInput.Validator = validateMinRatio
+	validated = append(validated, minRatioInput)
+	minRatioItem := widget.NewFormItem("Minimum Ratio", minRatioInput)
+	minRatioItem.HintText = "Warns in the library when the all-time share ratio drops below this, as private trackers require"
+
+	autoRemoveNotifyInput := widget.NewCheck("Send a notification when a torrent is removed", nil)
+	autoRemoveNotifyInput.SetChecked(config.AutoRemoveNotify)
+
+	seedingForm := widget.NewForm(
+		seedingGoalItem,
+		autoRemoveItem,
+		graceItem,
+		widget.NewFormItem("", autoRemoveNotifyInput),
+		minRatioItem,
+	)
+
+	// Network settings, applied when the client is created on launch
+
+	ipStackInput := widget.NewSelect(IPStackNames, nil)
+	ipStackInput.SetSelected(config.IPStack.String())
+	ipStackItem := widget.NewFormItem("IP Versions", ipStackInput)
+
+	proxyTypeInput := widget.NewSelect(ProxyTypeNames, nil)
+	proxyAddressInput := widget.NewEntry()
+	proxyAddressInput.SetPlaceHolder("127.0.0.1:1080")
+	proxyAddressInput.SetText(config.Proxy.Address)
+	proxyUsernameInput := widget.NewEntry()
+	proxyUsernameInput.SetPlaceHolder("Optional")
+	proxyUsernameInput.SetText(config.Proxy.Username)
+	proxyPasswordInput := widget.NewPasswordEntry()
+	proxyPasswordInput.SetPlaceHolder("Optional")
+	proxyPasswordInput.SetText(config.Proxy.Password)
+
+	// The address is only required, and checked, when a proxy is chosen
+	proxyAddressInput.Validator = func(address string) error {
+		if ParseProxyType(proxyTypeInput.Selected) == ProxyNone {
+			return nil
+		}
+		return validateProxyAddress(address)
+	}
+	validated = append(validated, proxyAddressInput)
+
+	proxyTypeInput.OnChanged = func(name string) {
+		if ParseProxyType(name) == ProxyNone {
+			proxyAddressInput.Disable()
+			proxyUsernameInput.Disable()
+			proxyPasswordInput.Disable()
+		} else {
+			proxyAddressInput.Enable()
+			proxyUsernameInput.Enable()
+			proxyPasswordInput.Enable()
+		}
+		proxyAddressInput.Validate()
+	}
+	proxyTypeInput.SetSelected(config.Proxy.Type.String())
+
+	proxyTypeItem := widget.NewFormItem("Proxy", proxyTypeInput)
+	proxyTypeItem.HintText = "Peers are only reached over TCP through a proxy; uTP, DHT and UDP trackers are turned off"
+
+	// Offer the current interfaces, though any name or IP can be typed, such as a VPN
+	// interface that isn't up yet
+	bindInput := widget.NewSelectEntry(interfaceNames())
+	bindInput.SetPlaceHolder("Any interface")
+	bindInput.SetText(config.BindInterface)
+	bindItem := widget.NewFormItem("Bind To", bindInput)
+	bindItem.HintText = "An interface such as tun0, or a source IP address"
+
+	killSwitchInput := widget.NewCheck("Pause all torrents while the bound interface is down", nil)
+	killSwitchInput.SetChecked(config.KillSwitch)
+
+	networkForm := widget.NewForm(
+		ipStackItem,
+		proxyTypeItem,
+		widget.NewFormItem("Proxy Address", proxyAddressInput),
+		widget.NewFormItem("Username", proxyUsernameInput),
+		widget.NewFormItem("Password", proxyPasswordInput),
+		bindItem,
+		widget.NewFormItem("", killSwitchInput),
+	)
+	networkNote := widget.NewLabel("Network settings take effect after restarting Reed.")
+	networkNote.Wrapping = fyne.TextWrapWord
+	networkContent := container.NewVBox(networkForm, networkNote)
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem("General", container.NewVScroll(generalForm)),
+		container.NewTabItem("Speed", container.NewVScroll(speedContent)),
+		container.NewTabItem("Seeding", container.NewVScroll(seedingForm)),
+		container.NewTabItem("Network", container.NewVScroll(networkContent)),
+	)
+
+	var settingsDialog *dialog.CustomDialog
+
+	// save applies the entered settings and closes the dialog
+	save := func(proxy ProxySettings) {
+		config.Categories = parseCategories(categoriesInput.Text)
+		config.SavePathTemplate = strings.TrimSpace(templateInput.Text)
+		config.CheckExistingFiles = checkExistingInput.Checked
+		config.Preallocate = preallocateInput.Checked
+		config.StartupPolicy = ParseStartupPolicy(startupPolicyInput.Selected)
+		config.BatchErrorLimit = parseErrorStreak(batchErrorInput.Text)
+		config.ErrorPauseLimit = parseErrorPauseLimit(errorPauseInput.Text)
+		config.TorrentURLTimeout = parseTorrentURLTimeout(urlTimeoutInput.Text)
+		config.TorrentURLMaxSize = parseTorrentURLMaxSize(urlMaxSizeInput.Text)
+		config.LogLevel = ParseLogLevel(logLevelInput.Selected)
+		if resetColumnsInput.Checked {
+			config.FileColumnWidths = nil
+			config.PeerColumnWidths = nil
+		}
+		config.ShowSwarmTotals = swarmTotalsInput.Checked
+		config.ShowSpeedGraph = speedGraphInput.Checked
+		config.ConfirmRemove = confirmRemoveInput.Checked
+		config.KeepActivity = keepActivityInput.Checked
+		config.CommandPalette = commandPaletteInput.Checked
+		config.WatchClipboard = watchClipboardInput.Checked
+		config.VerifyMagnets = verifyMagnetsInput.Checked
+		config.WebhookEnabled = webhookEnabledInput.Checked
+		config.WebhookURL = strings.TrimSpace(webhookURLInput.Text)
+
+		config.DownloadLimit = parseSpeedLimit(downloadLimitInput)
+		config.UploadLimit = parseSpeedLimit(uploadLimitInput)
+		config.AltDownloadLimit = parseSpeedLimit(altDownloadLimitInput)
+		config.AltUploadLimit = parseSpeedLimit(altUploadLimitInput)
+		config.UploadSlots = parseUploadSlots(uploadSlotsInput.Text)
+		config.MaxConnections = parseMaxConnections(maxConnectionsInput.Text)
+		config.MaxActiveDownloads = parseActiveDownloads(activeDownloadsInput.Text)
+		config.PauseOnBattery = pauseOnBatteryInput.Checked
+		config.BatteryThreshold = parseBatteryThreshold(batteryThresholdInput.Text)
+		config.AltSpeedEnabled = altSpeedInput.Checked
+		config.ScheduleEnabled = scheduleEnabledInput.Checked
+		config.Schedule = schedule
+
+		config.SeedingGoalHours = parseSeedingGoal(seedingGoalInput.Text)
+		config.AutoRemove = autoRemoveInput.Checked
+		config.AutoRemoveGrace = parseGracePeriod(graceInput.Text)
+		config.AutoRemoveNotify = autoRemoveNotifyInput.Checked
+		config.MinRatio = parseMinRatio(minRatioInput.Text)
+
+		config.IPStack = ParseIPStack(ipStackInput.Selected)
+		config.Proxy = proxy
+		config.BindInterface = strings.TrimSpace(bindInput.Text)
+		config.KillSwitch = killSwitchInput.Checked
+
+		settingsDialog.Hide()
+
+		if err := config.Save(); err != nil {
+			dialog.ShowError(fmt.Errorf("error saving settings: %v", err), w)
+		}
+		if onSaved != nil {
+			onSaved()
+		}
+	}
+
+	var saveButton, cancelButton *widget.Button
+	saveButton = widget.NewButtonWithIcon("Save", theme.ConfirmIcon(), func() {
+		proxy := ProxySettings{
+			Type:     ParseProxyType(proxyTypeInput.Selected),
+			Address:  strings.TrimSpace(proxyAddressInput.Text),
+			Username: proxyUsernameInput.Text,
+			Password: proxyPasswordInput.Text,
+		}
+		if !proxy.Enabled() {
+			proxy = ProxySettings{}
+		}
+		if !proxy.Enabled() || proxy == config.Proxy {
+			save(proxy)
+			return
+		}
+
+		// Make sure a new proxy is reachable before saving it, without blocking the UI
+		saveButton.Disable()
+		cancelButton.Disable()
+		go func() {
+			err := checkProxy(proxy, 5*time.Second)
+			fyne.Do(func() {
+				saveButton.Enable()
+				cancelButton.Enable()
+				if err != nil {
+					dialog.ShowError(err, w)
+					return
+				}
+				save(proxy)
+			})
+		}()
+	})
+	saveButton.Importance = widget.HighImportance
+
+	// Only allow saving when every field is valid
+	updateSaveButton := func(error) {
+		for _, entry := range validated {
+			if entry.Validate() != nil {
+				saveButton.Disable()
+				return
+			}
+		}
+		saveButton.Enable()
+	}
+	for _, entry := range validated {
+		entry.SetOnValidationChanged(updateSaveButton)
+	}
+	updateSaveButton(nil)
+
+	cancelButton = widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), func() {
+		settingsDialog.Hide()
+	})
+
+	settingsDialog = dialog.NewCustomWithoutButtons("Settings", tabs, w)
+	settingsDialog.SetButtons([]fyne.CanvasObject{cancelButton, saveButton})
+	settingsDialog.Resize(fyne.NewSize(700, 520))
+	settingsDialog.Show()
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// snackbar is a bar that briefly offers an action, such as undoing a removal. Only one
+// message is shown at a time; showing another one expires the current message first.
+type snackbar struct {
+	Content *fyne.Container
+
+	label  *widget.Label
+	action *widget.Button
+
+	timer    *time.Timer
+	onExpire func()
+}
+
+// newSnackbar creates a hidden snackbar
+func newSnackbar() *snackbar {
+	s := &snackbar{
+		label:  widget.NewLabel(""),
+		action: widget.NewButton("", nil),
+	}
+	s.action.Importance = widget.HighImportance
+
+	closeButton := widget.NewButtonWithIcon("", theme.CancelIcon(), s.Dismiss)
+	closeButton.Importance = widget.LowImportance
+
+	background := canvas.NewRectangle(theme.Color(theme.ColorNameInputBackground))
+	s.Content = container.NewStack(
+		background,
+		container.NewPadded(container.NewBorder(nil, nil, nil,
+			container.NewHBox(s.action, closeButton), s.label)),
+	)
+	s.Content.Hide()
+	return s
+}
+
+// Show displays message with an action button for d. onExpire is called if the action
+// wasn't used by the time the message goes away.
+func (s *snackbar) Show(message, actionName string, action func(), onExpire func(), d time.Duration) {
+	s.Dismiss()
+
+	s.label.SetText(message)
+	s.action.SetText(actionName)
+	s.action.OnTapped = func() {
+		s.stop()
+		s.Content.Hide()
+		action()
+	}
+	s.onExpire = onExpire
+	s.Content.Show()
+
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		fyne.Do(func() {
+			// Ignore a timer that fired just as a newer message replaced this one
+			if s.timer == timer {
+				s.Dismiss()
+			}
+		})
+	})
+	s.timer = timer
+}
+
+// Dismiss hides the current message, expiring it
+func (s *snackbar) Dismiss() {
+	onExpire := s.onExpire
+	s.stop()
+	s.Content.Hide()
+	if onExpire != nil {
+		onExpire()
+	}
+}
+
+// stop cancels the timer of the current message without expiring it
+func (s *snackbar) stop() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.onExpire = nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// sparklineSize is the minimum size of a sparkline
+var sparklineSize = fyne.NewSize(120, 24)
+
+// sparkline is a small line graph of a speed history, scaled to its largest sample
+type sparkline struct {
+	widget.BaseWidget
+
+	history *speedHistory
+}
+
+// newSparkline creates a sparkline drawing history. Call Refresh after adding samples.
+func newSparkline(history *speedHistory) *sparkline {
+	s := &sparkline{history: history}
+	s.ExtendBaseWidget(s)
+	return s
+}
+
+// SetHistory draws history instead of the current one
+func (s *sparkline) SetHistory(history *speedHistory) {
+	s.history = history
+	s.Refresh()
+}
+
+// CreateRenderer builds the background and line segments of the graph
+func (s *sparkline) CreateRenderer() fyne.WidgetRenderer {
+	r := &sparklineRenderer{
+		sparkline:  s,
+		background: canvas.NewRectangle(theme.Color(theme.ColorNameInputBackground)),
+	}
+	r.Refresh()
+	return r
+}
+
+type sparklineRenderer struct {
+	sparkline  *sparkline
+	background *canvas.Rectangle
+	lines      []*canvas.Line
+	objects    []fyne.CanvasObject
+}
+
+func (r *sparklineRenderer) Layout(size fyne.Size) {
+	r.background.Resize(size)
+
+	values := r.sparkline.history.Values()
+	highest := r.sparkline.history.Max()
+	capacity := r.sparkline.history.Size()
+	if capacity < 2 {
+		return
+	}
+
+	// Newest sample on the right edge, scaled so the highest one reaches the top
+	step := size.Width / float32(capacity-1)
+	offset := float32(capacity - len(values))
+	point := func(i int) fyne.Position {
+		y := size.Height - 1
+		if highest > 0 {
+			y = (size.Height - 1) * (1 - float32(values[i])/float32(highest))
+		}
+		return fyne.NewPos((offset+float32(i))*step, y)
+	}
+	for i, line := range r.lines {
+		line.Position1 = point(i)
+		line.Position2 = point(i + 1)
+	}
+}
+
+func (r *sparklineRenderer) MinSize() fyne.Size {
+	return sparklineSize
+}
+
+func (r *sparklineRenderer) Refresh() {
+	// One segment between each pair of samples
+	segments := max(len(r.sparkline.history.Values())-1, 0)
+	for len(r.lines) < segments {
+		line := canvas.NewLine(theme.Color(theme.ColorNamePrimary))
+		line.StrokeWidth = 1.5
+		r.lines = append(r.lines, line)
+	}
+	r.lines = r.lines[:segments]
+
+	r.objects = []fyne.CanvasObject{r.background}
+	for _, line := range r.lines {
+		line.StrokeColor = theme.Color(theme.ColorNamePrimary)
+		r.objects = append(r.objects, line)
+	}
+	r.background.FillColor = theme.Color(theme.ColorNameInputBackground)
+
+	r.Layout(r.sparkline.Size())
+	canvas.Refresh(r.sparkline)
+}
+
+func (r *sparklineRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *sparklineRenderer) Destroy() {}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/anacrolix/torrent"
+)
+
+// BandwidthSample splits the bytes transferred into torrent data (payload) and everything
+// else on the wire, such as handshakes, piece requests and encryption (overhead)
+type BandwidthSample struct {
+	PayloadIn   int64
+	OverheadIn  int64
+	PayloadOut  int64
+	OverheadOut int64
+}
+
+// newBandwidthSample reads a sample from anacrolix connection stats
+func newBandwidthSample(stats torrent.ConnStats) BandwidthSample {
+	read := stats.BytesRead.Int64()
+	readData := stats.BytesReadData.Int64()
+	written := stats.BytesWritten.Int64()
+	writtenData := stats.BytesWrittenData.Int64()
+
+	return BandwidthSample{
+		PayloadIn:   readData,
+		OverheadIn:  max(read-readData, 0),
+		PayloadOut:  writtenData,
+		OverheadOut: max(written-writtenData, 0),
+	}
+}
+
+// RateSince returns the bytes per second transferred between an earlier sample and this one
+func (s BandwidthSample) RateSince(prev BandwidthSample, elapsed time.Duration) BandwidthSample {
+	seconds := elapsed.Seconds()
+	if seconds <= 0 {
+		return BandwidthSample{}
+	}
+	rate := func(now, before int64) int64 {
+		return max(int64(float64(now-before)/seconds), 0)
+	}
+	return BandwidthSample{
+		PayloadIn:   rate(s.PayloadIn, prev.PayloadIn),
+		OverheadIn:  rate(s.OverheadIn, prev.OverheadIn),
+		PayloadOut:  rate(s.PayloadOut, prev.PayloadOut),
+		OverheadOut: rate(s.OverheadOut, prev.OverheadOut),
+	}
+}
+
+// overheadShare formats the fraction of traffic that was overhead
+func overheadShare(payload, overhead int64) string {
+	if payload+overhead == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(overhead)*100/float64(payload+overhead))
+}
+
+// statisticsView shows the session's bandwidth broken down into payload and overhead
+type statisticsView struct {
+	Content fyne.CanvasObject
+
+	// Labels for the incoming and outgoing rows
+	inLabels  [5]*widget.Label
+	outLabels [5]*widget.Label
+
+	connectionsLabel *widget.Label
+	sessionRatio     *widget.Label
+	allTimeRatio     *widget.Label
+
+	prev     BandwidthSample
+	prevTime time.Time
+}
+
+// newStatisticsView creates an empty statistics view
+func newStatisticsView() *statisticsView {
+	v := &statisticsView{
+		connectionsLabel: widget.NewLabel("-"),
+		sessionRatio:     widget.NewLabel("-"),
+		allTimeRatio:     widget.NewLabel("-"),
+	}
+
+	grid := container.NewGridWithColumns(6)
+	for _, heading := range []string{"", "Payload", "Overhead", "Payload Rate", "Overhead Rate", "Overhead Share"} {
+		grid.Add(widget.NewLabelWithStyle(heading, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	}
+	addRow := func(name string, labels *[5]*widget.Label) {
+		grid.Add(widget.NewLabel(name))
+		for i := range labels {
+			labels[i] = widget.NewLabel("-")
+			grid.Add(labels[i])
+		}
+	}
+	addRow("Incoming", &v.inLabels)
+	addRow("Outgoing", &v.outLabels)
+
+	note := widget.NewLabel("Payload is torrent data. Overhead is everything else sent over the wire, " +
+		"including handshakes, piece requests and encryption. Totals cover this session.")
+	note.Wrapping = fyne.TextWrapWord
+
+	v.Content = container.NewVBox(
+		widget.NewLabelWithStyle("Bandwidth", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		grid,
+		note,
+		widget.NewLabelWithStyle("Connections", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		v.connectionsLabel,
+		widget.NewLabelWithStyle("Share Ratio", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewForm(
+			widget.NewFormItem("This Session", v.sessionRatio),
+			widget.NewFormItem("All Time", v.allTimeRatio),
+		),
+	)
+	return v
+}
+
+// SetRatio shows the share ratio of this session and of all time, counting torrent data
+// only. The all-time ratio is marked when it is below minimum (0 meaning no minimum).
+func (v *statisticsView) SetRatio(session, allTime TransferTotals, minimum float64) {
+	v.sessionRatio.SetText(session.String())
+
+	text := allTime.String()
+	importance := widget.MediumImportance
+	if allTime.Below(minimum) {
+		text += fmt.Sprintf(", below the minimum of %.2f", minimum)
+		importance = widget.WarningImportance
+	}
+	if v.allTimeRatio.Importance != importance {
+		v.allTimeRatio.Importance = importance
+		v.allTimeRatio.Refresh()
+	}
+	v.allTimeRatio.SetText(text)
+}
+
+// SetConnections shows how many peer connections are established, against the global
+// limit (0 meaning unlimited)
+func (v *statisticsView) SetConnections(count, limit int) {
+	if limit > 0 {
+		v.connectionsLabel.SetText(fmt.Sprintf("%d established of at most %d", count, limit))
+	} else {
+		v.connectionsLabel.SetText(fmt.Sprintf("%d established, no limit", count))
+	}
+}
+
+// Update shows the latest client stats, working out rates from the previous update
+func (v *statisticsView) Update(stats torrent.ConnStats, now time.Time) {
+	sample := newBandwidthSample(stats)
+
+	var rate BandwidthSample
+	if !v.prevTime.IsZero() {
+		rate = sample.RateSince(v.prev, now.Sub(v.prevTime))
+	}
+	v.prev = sample
+	v.prevTime = now
+
+	setRow := func(labels [5]*widget.Label, payload, overhead, payloadRate, overheadRate int64) {
+		labels[0].SetText(HumanReadableSize(payload))
+		labels[1].SetText(HumanReadableSize(overhead))
+		labels[2].SetText(HumanReadableRate(payloadRate))
+		labels[3].SetText(HumanReadableRate(overheadRate))
+		labels[4].SetText(overheadShare(payload, overhead))
+	}
+	setRow(v.inLabels, sample.PayloadIn, sample.OverheadIn, rate.PayloadIn, rate.OverheadIn)
+	setRow(v.outLabels, sample.PayloadOut, sample.OverheadOut, rate.PayloadOut, rate.OverheadOut)
+}
+
+// torrentGraphSamples is how many speed samples the details panel keeps for the shown
+// torrent, one per update
+const torrentGraphSamples = 120
+
+// torrentSpeedGraph shows the recent download and upload speeds of the torrent in the
+// details panel. Only that torrent records a history, so memory stays bounded however
+// many torrents there are.
+type torrentSpeedGraph struct {
+	Content fyne.CanvasObject
+
+	item          *TorrentItem
+	downloadGraph *sparkline
+	uploadGraph   *sparkline
+	downloadLabel *widget.Label
+	uploadLabel   *widget.Label
+}
+
+// newTorrentSpeedGraph creates an empty graph
+func newTorrentSpeedGraph() *torrentSpeedGraph {
+	g := &torrentSpeedGraph{
+		downloadGraph: newSparkline(newSpeedHistory(torrentGraphSamples)),
+		uploadGraph:   newSparkline(newSpeedHistory(torrentGraphSamples)),
+		downloadLabel: widget.NewLabel(""),
+		uploadLabel:   widget.NewLabel(""),
+	}
+	g.Content = widget.NewForm(
+		widget.NewFormItem("Download", container.NewBorder(nil, nil, nil, g.downloadLabel, g.downloadGraph)),
+		widget.NewFormItem("Upload", container.NewBorder(nil, nil, nil, g.uploadLabel, g.uploadGraph)),
+	)
+	return g
+}
+
+// SetTorrent starts a fresh history for item, dropping the one of the torrent shown before
+func (g *torrentSpeedGraph) SetTorrent(item *TorrentItem) {
+	if g.item != nil {
+		g.item.downloadHistory = nil
+		g.item.uploadHistory = nil
+	}
+	g.item = item
+	downloads, uploads := newSpeedHistory(torrentGraphSamples), newSpeedHistory(torrentGraphSamples)
+	if item != nil {
+		item.downloadHistory = downloads
+		item.uploadHistory = uploads
+	}
+	g.downloadGraph.SetHistory(downloads)
+	g.uploadGraph.SetHistory(uploads)
+	g.Update()
+}
+
+// Update redraws the graphs with the samples recorded since the last update
+func (g *torrentSpeedGraph) Update() {
+	g.downloadLabel.SetText("Peak " + HumanReadableRate(g.downloadGraph.history.Max()))
+	g.uploadLabel.SetText("Peak " + HumanReadableRate(g.uploadGraph.history.Max()))
+	g.downloadGraph.Refresh()
+	g.uploadGraph.Refresh()
+}
+
+// speedHistory is a fixed-size ring buffer of speed samples in bytes per second. Once full,
+// each new sample replaces the oldest one.
+type speedHistory struct {
+	samples []int64
+	next    int
+	full    bool
+}
+
+// newSpeedHistory creates a history holding the last size samples
+func newSpeedHistory(size int) *speedHistory {
+	return &speedHistory{samples: make([]int64, size)}
+}
+
+// Add records a sample
+func (h *speedHistory) Add(sample int64) {
+	h.samples[h.next] = sample
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Values returns the recorded samples, oldest first
+func (h *speedHistory) Values() []int64 {
+	if !h.full {
+		return append([]int64(nil), h.samples[:h.next]...)
+	}
+	return append(append([]int64(nil), h.samples[h.next:]...), h.samples[:h.next]...)
+}
+
+// Size returns how many samples the history holds once full
+func (h *speedHistory) Size() int {
+	return len(h.samples)
+}
+
+// Max returns the largest recorded sample
+func (h *speedHistory) Max() int64 {
+	var highest int64
+	for _, sample := range h.Values() {
+		highest = max(highest, sample)
+	}
+	return highest
+}
+
+// LibrarySummary is how far along the torrents in the library are as a whole
+type LibrarySummary struct {
+	Torrents    int
+	Completion  float64 // Fraction of all the data downloaded, weighted by size
+	Downloading int     // Incomplete torrents that are transferring, as the Downloading filter shows
+	Seeding     int     // Complete torrents connected to peers
+	Complete    int     // Every complete torrent, seeding or not
+}
+
+// newLibrarySummary adds up the progress of every torrent
+func newLibrarySummary(torrents map[string]*TorrentItem) LibrarySummary {
+	s := LibrarySummary{Torrents: len(torrents)}
+	var size, downloaded int64
+	for _, item := range torrents {
+		if item == nil {
+			continue
+		}
+		if item.Size > 0 {
+			size += item.Size
+			downloaded += min(item.Downloaded, item.Size)
+		}
+		switch {
+		case item.Progress >= 1.0:
+			s.Complete++
+			if item.Peers > 0 {
+				s.Seeding++
+			}
+		case FilterDownloading.Matches(item) && !item.Checking:
+			s.Downloading++
+		}
+	}
+	if size > 0 {
+		s.Completion = float64(downloaded) / float64(size)
+	}
+	return s
+}
+
+// String formats the summary for the Library header
+func (s LibrarySummary) String() string {
+	if s.Torrents == 0 {
+		return "0 Torrents"
+	}
+	return fmt.Sprintf("%d Torrents · %.1f%% done · %d downloading, %d seeding, %d complete",
+		s.Torrents, s.Completion*100, s.Downloading, s.Seeding, s.Complete)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// tooltipLabel is a label that shows Tooltip in a small pop-up while the mouse is over
+// it. Fyne has no tooltips of its own.
+type tooltipLabel struct {
+	widget.Label
+
+	// Tooltip is shown on hover, nothing being shown while it is empty
+	Tooltip string
+
+	popUp *widget.PopUp
+}
+
+// newTooltipLabel creates a label showing text
+func newTooltipLabel(text string) *tooltipLabel {
+	l := &tooltipLabel{}
+	l.Text = text
+	l.ExtendBaseWidget(l)
+	return l
+}
+
+// MouseIn implements desktop.Hoverable
+func (l *tooltipLabel) MouseIn(e *desktop.MouseEvent) {
+	if l.Tooltip == "" {
+		return
+	}
+	canvas := fyne.CurrentApp().Driver().CanvasForObject(l)
+	if canvas == nil {
+		return
+	}
+	l.hideTooltip()
+	l.popUp = widget.NewPopUp(widget.NewLabel(l.Tooltip), canvas)
+	l.popUp.ShowAtPosition(e.AbsolutePosition.Add(fyne.NewPos(0, theme.Padding()*4)))
+}
+
+// MouseMoved implements desktop.Hoverable
+func (l *tooltipLabel) MouseMoved(*desktop.MouseEvent) {}
+
+// MouseOut implements desktop.Hoverable
+func (l *tooltipLabel) MouseOut() {
+	l.hideTooltip()
+}
+
+// hideTooltip removes the pop-up if it is showing
+func (l *tooltipLabel) hideTooltip() {
+	if l.popUp != nil {
+		l.popUp.Hide()
+		l.popUp = nil
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// A fetch of a .torrent file that fails for a reason that may pass, such as a timeout or a
+// busy server, is tried torrentURLAttempts times in all, waiting longer after each failure
+const (
+	torrentURLAttempts = 3
+	torrentURLBackoff  = 2 * time.Second
+)
+
+// IsTorrentURL reports whether text is an http or https link to a .torrent file
+func IsTorrentURL(text string) bool {
+	u, err := url.Parse(strings.TrimSpace(text))
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	return strings.HasSuffix(strings.ToLower(u.Path), ".torrent")
+}
+
+// FetchTorrentURL downloads and parses the .torrent file at link with client, whose timeout
+// limits each attempt. A file larger than maxSize bytes is refused. offline is checked
+// before each retry, which is skipped if it returns an error. The file is written to a
+// temporary file as it arrives rather than held in memory.
+func FetchTorrentURL(client *http.Client, link string, maxSize int64, offline func() error) (*metainfo.MetaInfo, error) {
+	var path string
+	var err error
+	for attempt := 1; ; attempt++ {
+		var transient bool
+		path, transient, err = downloadTorrentURL(client, strings.TrimSpace(link), maxSize)
+		if err == nil || !transient {
+			break
+		}
+		if attempt == torrentURLAttempts {
+			return nil, fmt.Errorf("after %d attempts: %v", torrentURLAttempts, err)
+		}
+		time.Sleep(time.Duration(attempt) * torrentURLBackoff)
+		if offlineErr := offline(); offlineErr != nil {
+			return nil, offlineErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+
+	mi, err := metainfo.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("not a valid .torrent file: %v", err)
+	}
+	return mi, nil
+}
+
+// downloadTorrentURL makes one attempt at saving the file at link to a temporary file,
+// returning its path. On failure it reports whether trying again might work.
+func downloadTorrentURL(client *http.Client, link string, maxSize int64) (path string, transient bool, err error) {
+	resp, err := client.Get(link)
+	if err != nil {
+		return "", true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", retryableStatus(resp.StatusCode), fmt.Errorf("server returned %s", resp.Status)
+	}
+	tooLarge := fmt.Errorf("the file is larger than the %s limit", HumanReadableSize(maxSize))
+	if resp.ContentLength > maxSize {
+		return "", false, tooLarge
+	}
+
+	f, err := os.CreateTemp("", "reed-*.torrent")
+	if err != nil {
+		return "", false, fmt.Errorf("error creating temporary file: %v", err)
+	}
+	defer f.Close()
+
+	// Read one byte past the limit to tell a file of exactly maxSize from a larger one
+	n, err := io.Copy(f, io.LimitReader(resp.Body, maxSize+1))
+	if err == nil && n > maxSize {
+		err = tooLarge
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err != tooLarge, err
+	}
+	return f.Name(), false, nil
+}
+
+// retryableStatus reports whether a request that got an HTTP status code may work if it is
+// made again, such as after a timeout or a busy server, unlike a permanent error like 404
+func retryableStatus(code int) bool {
+	return code >= 500 || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+}
+
+// validateTorrentURLTimeout accepts a whole number of seconds of at least 1
+func validateTorrentURLTimeout(text string) error {
+	seconds, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || seconds < 1 {
+		return fmt.Errorf("enter a whole number of seconds, at least 1")
+	}
+	return nil
+}
+
+// parseTorrentURLTimeout reads a validated timeout in seconds
+func parseTorrentURLTimeout(text string) int {
+	seconds, _ := strconv.Atoi(strings.TrimSpace(text))
+	return seconds
+}
+
+// validateTorrentURLMaxSize accepts a whole number of MiB of at least 1
+func validateTorrentURLMaxSize(text string) error {
+	size, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || size < 1 {
+		return fmt.Errorf("enter a whole number of MiB, at least 1")
+	}
+	return nil
+}
+
+// parseTorrentURLMaxSize reads a validated size limit in MiB
+func parseTorrentURLMaxSize(text string) int {
+	size, _ := strconv.Atoi(strings.TrimSpace(text))
+	return size
+}
+
+// copiedTorrent returns the magnet link or .torrent URL held in clipboard text, and a
+// name to show for it, or an empty link if the text is neither
+func copiedTorrent(text string) (link, name string) {
+	text = strings.TrimSpace(text)
+	if strings.ContainsAny(text, "\r\n") {
+		return "", ""
+	}
+	if ValidateMagnetLink(text) == nil {
+		m, _ := metainfo.ParseMagnetUri(text)
+		name = m.DisplayName
+		if name == "" {
+			name = m.InfoHash.HexString()
+		}
+		return text, name
+	}
+	if IsTorrentURL(text) {
+		u, _ := url.Parse(text)
+		name = u.Path[strings.LastIndex(u.Path, "/")+1:]
+		return text, name
+	}
+	return "", ""
+}
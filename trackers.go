@@ -0,0 +1,492 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// ValidateTrackerURL checks that s is an announce URL anacrolix can use
+func ValidateTrackerURL(s string) error {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("invalid tracker URL: %v", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "udp", "ws", "wss":
+	default:
+		return fmt.Errorf("unsupported tracker scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("tracker URL has no host")
+	}
+	return nil
+}
+
+// ParseTrackerURLs splits a pasted block of tracker URLs, separated by whitespace or
+// commas, into the valid ones without duplicates and the rejected ones
+func ParseTrackerURLs(text string) (valid, invalid []string) {
+	seen := make(map[string]bool)
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+	})
+	for _, field := range fields {
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+		if ValidateTrackerURL(field) != nil {
+			invalid = append(invalid, field)
+			continue
+		}
+		valid = append(valid, field)
+	}
+	return valid, invalid
+}
+
+// torrentTrackers returns the announce list anacrolix is currently using for a torrent,
+// leaving out empty tiers
+func torrentTrackers(mi metainfo.MetaInfo) [][]string {
+	tiers := make([][]string, 0)
+	for _, tier := range mi.UpvertedAnnounceList() {
+		if len(tier) > 0 {
+			tiers = append(tiers, append([]string(nil), tier...))
+		}
+	}
+	return tiers
+}
+
+// flattenTrackers lists the URLs of an announce list in order, without duplicates
+func flattenTrackers(tiers [][]string) []string {
+	seen := make(map[string]bool)
+	urls := make([]string, 0)
+	for _, tier := range tiers {
+		for _, u := range tier {
+			if !seen[u] {
+				seen[u] = true
+				urls = append(urls, u)
+			}
+		}
+	}
+	return urls
+}
+
+// trackerTiers turns an ordered list of URLs into an announce list, one tracker per tier
+func trackerTiers(urls []string) [][]string {
+	tiers := make([][]string, 0, len(urls))
+	for _, u := range urls {
+		tiers = append(tiers, []string{u})
+	}
+	return tiers
+}
+
+// cloneTiers copies an announce list so editing the copy leaves the original alone
+func cloneTiers(tiers [][]string) [][]string {
+	clone := make([][]string, 0, len(tiers))
+	for _, tier := range tiers {
+		clone = append(clone, append([]string(nil), tier...))
+	}
+	return clone
+}
+
+// dropEmptyTiers removes the tiers left without trackers
+func dropEmptyTiers(tiers [][]string) [][]string {
+	kept := make([][]string, 0, len(tiers))
+	for _, tier := range tiers {
+		if len(tier) > 0 {
+			kept = append(kept, tier)
+		}
+	}
+	return kept
+}
+
+// moveToTier moves a tracker into the tier above (delta -1) or below (delta 1) its own.
+// Moving it past the first or last tier gives it a new tier of its own there.
+func moveToTier(tiers [][]string, tier, index, delta int) [][]string {
+	moved := cloneTiers(tiers)
+	u := moved[tier][index]
+	moved[tier] = append(moved[tier][:index], moved[tier][index+1:]...)
+
+	switch target := tier + delta; {
+	case target < 0:
+		moved = append([][]string{{u}}, moved...)
+	case target >= len(moved):
+		moved = append(moved, []string{u})
+	default:
+		moved[target] = append(moved[target], u)
+	}
+	return dropEmptyTiers(moved)
+}
+
+// withoutTrackers returns an announce list without the URLs in drop, dropping emptied tiers
+func withoutTrackers(tiers [][]string, drop map[string]bool) [][]string {
+	kept := make([][]string, 0, len(tiers))
+	for _, tier := range tiers {
+		keptTier := make([]string, 0, len(tier))
+		for _, u := range tier {
+			if !drop[u] {
+				keptTier = append(keptTier, u)
+			}
+		}
+		kept = append(kept, keptTier)
+	}
+	return dropEmptyTiers(kept)
+}
+
+// activeTier returns the tier in use, the way BEP 12 picks it: the first one with a tracker
+// whose last announce succeeded. It is -1 while no tracker has answered.
+func activeTier(tiers [][]string, statuses map[string]TrackerStatus) int {
+	for i, tier := range tiers {
+		for _, u := range tier {
+			if status, ok := statuses[u]; ok && status.working() {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// TrackerStatus is the state of one tracker's announces as reported by anacrolix
+type TrackerStatus struct {
+	NextAnnounce string // When the next announce is due, or "anytime"
+	LastResult   string // Peers returned by the last announce, "never", or its error
+	Failing      bool   // Whether the last announce failed
+}
+
+// announcePeers matches the result of a successful announce
+var announcePeers = regexp.MustCompile(`^\d+ peers$`)
+
+// parseTrackerStatuses reads the tracker states of one torrent out of the client status
+// text written by anacrolix's Client.WriteStatus, which is the only place it exposes the
+// state of its announcers. Each enabled tracker is listed under the torrent's info-hash as
+// a quoted URL followed by "next ann: ..., last ann: ...".
+func parseTrackerStatuses(status, infoHash string) map[string]TrackerStatus {
+	statuses := make(map[string]TrackerStatus)
+	inTorrent := false
+
+	scanner := bufio.NewScanner(strings.NewReader(status))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if hash, ok := strings.CutPrefix(line, "Infohash: "); ok {
+			inTorrent = strings.EqualFold(hash, infoHash)
+			continue
+		}
+		if !inTorrent || !strings.HasPrefix(line, `"`) {
+			continue
+		}
+
+		quoted, err := strconv.QuotedPrefix(line)
+		if err != nil {
+			continue
+		}
+		u, err := strconv.Unquote(quoted)
+		if err != nil {
+			continue
+		}
+		rest := strings.TrimSpace(line[len(quoted):])
+		next, last, ok := strings.Cut(strings.TrimPrefix(rest, "next ann: "), ", last ann: ")
+		if !ok {
+			// Other kinds of announcer, such as WebSocket trackers, report free-form status
+			statuses[u] = TrackerStatus{LastResult: rest}
+			continue
+		}
+		statuses[u] = TrackerStatus{
+			NextAnnounce: next,
+			LastResult:   last,
+			Failing:      last != "never" && !announcePeers.MatchString(last),
+		}
+	}
+	return statuses
+}
+
+// working reports whether the tracker's last announce returned peers
+func (s TrackerStatus) working() bool {
+	return !s.Failing && announcePeers.MatchString(s.LastResult)
+}
+
+// describe summarises a tracker's status for the Trackers tab
+func (s TrackerStatus) describe() string {
+	switch {
+	case s.Failing:
+		return fmt.Sprintf("Error: %s (retry in %s)", s.LastResult, s.NextAnnounce)
+	case s.NextAnnounce == "":
+		return s.LastResult
+	case s.LastResult == "never":
+		return "Not announced yet"
+	}
+	return fmt.Sprintf("%s, next announce in %s", s.LastResult, s.NextAnnounce)
+}
+
+// trackerEntry places one row of the Trackers tab in the announce list
+type trackerEntry struct {
+	tier  int
+	index int
+}
+
+// trackerEditor edits the announce list of the torrent shown in the details panel, tier by
+// tier. It keeps its own copy of the list so the once-a-second refresh doesn't discard
+// unsaved edits.
+type trackerEditor struct {
+	Content fyne.CanvasObject
+
+	item     *TorrentItem
+	tiers    [][]string
+	entries  []trackerEntry // One per tracker, in announce list order
+	changed  bool
+	statuses map[string]TrackerStatus
+
+	list        *widget.List
+	pasteInput  *widget.Entry
+	statusLabel *widget.Label
+	saveButton  *widget.Button
+
+	// OnSaved is called after the torrent's trackers have been replaced
+	OnSaved func()
+}
+
+// newTrackerEditor creates an editor showing no torrent
+func newTrackerEditor() *trackerEditor {
+	e := &trackerEditor{}
+
+	e.list = widget.NewList(
+		func() int {
+			return len(e.entries)
+		},
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil,
+				widget.NewLabel("Tier 00 (in use)"),
+				container.NewHBox(
+					widget.NewButtonWithIcon("", theme.MoveUpIcon(), nil),
+					widget.NewButtonWithIcon("", theme.MoveDownIcon(), nil),
+					widget.NewButtonWithIcon("", theme.DeleteIcon(), nil),
+				),
+				container.NewVBox(
+					widget.NewLabel("Tracker"),
+					widget.NewLabel("Status"),
+				),
+			)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if int(id) >= len(e.entries) {
+				return
+			}
+			entry := e.entries[id]
+			u := e.tiers[entry.tier][entry.index]
+
+			row := obj.(*fyne.Container)
+			labels := row.Objects[0].(*fyne.Container)
+			urlLabel := labels.Objects[0].(*widget.Label)
+			statusLabel := labels.Objects[1].(*widget.Label)
+			tierLabel := row.Objects[1].(*widget.Label)
+			buttons := row.Objects[2].(*fyne.Container)
+			upButton := buttons.Objects[0].(*widget.Button)
+			downButton := buttons.Objects[1].(*widget.Button)
+			removeButton := buttons.Objects[2].(*widget.Button)
+
+			// The first tier is the primary one and later tiers are its backups
+			tierText := fmt.Sprintf("Tier %d", entry.tier+1)
+			tierLabel.Importance = widget.LowImportance
+			if entry.tier == activeTier(e.tiers, e.statuses) {
+				tierText += " (in use)"
+				tierLabel.Importance = widget.SuccessImportance
+			}
+			tierLabel.SetText(tierText)
+			urlLabel.SetText(u)
+
+			// Failing trackers are shown in red with their last error
+			status, ok := e.statuses[u]
+			if ok {
+				statusLabel.SetText(status.describe())
+			} else {
+				statusLabel.SetText("Not announcing")
+			}
+			if ok && status.Failing {
+				urlLabel.Importance = widget.DangerImportance
+				statusLabel.Importance = widget.DangerImportance
+			} else {
+				urlLabel.Importance = widget.MediumImportance
+				statusLabel.Importance = widget.LowImportance
+			}
+			urlLabel.Refresh()
+			statusLabel.Refresh()
+			upButton.OnTapped = func() {
+				e.tiers = moveToTier(e.tiers, entry.tier, entry.index, -1)
+				e.markChanged()
+			}
+			downButton.OnTapped = func() {
+				e.tiers = moveToTier(e.tiers, entry.tier, entry.index, 1)
+				e.markChanged()
+			}
+			removeButton.OnTapped = func() {
+				e.tiers = withoutTrackers(e.tiers, map[string]bool{u: true})
+				e.markChanged()
+			}
+
+			// A tracker alone in the first or last tier has nowhere further to go
+			alone := len(e.tiers[entry.tier]) == 1
+			if entry.tier == 0 && alone {
+				upButton.Disable()
+			} else {
+				upButton.Enable()
+			}
+			if entry.tier == len(e.tiers)-1 && alone {
+				downButton.Disable()
+			} else {
+				downButton.Enable()
+			}
+		},
+	)
+
+	e.pasteInput = widget.NewMultiLineEntry()
+	e.pasteInput.SetPlaceHolder("Paste one or more tracker URLs")
+	e.pasteInput.SetMinRowsVisible(3)
+
+	addButton := widget.NewButtonWithIcon("Add", theme.ContentAddIcon(), func() {
+		valid, invalid := ParseTrackerURLs(e.pasteInput.Text)
+		existing := make(map[string]bool)
+		for _, u := range flattenTrackers(e.tiers) {
+			existing[u] = true
+		}
+
+		// New trackers go last, each in a backup tier of its own
+		added := 0
+		for _, u := range valid {
+			if !existing[u] {
+				e.tiers = append(e.tiers, []string{u})
+				added++
+			}
+		}
+		e.pasteInput.SetText("")
+		if added > 0 {
+			e.markChanged()
+		}
+		if len(invalid) > 0 {
+			e.statusLabel.SetText(fmt.Sprintf("Skipped %d invalid URL(s): %s", len(invalid), strings.Join(invalid, ", ")))
+		}
+	})
+
+	revertButton := widget.NewButton("Revert", func() {
+		e.SetTorrent(e.item)
+	})
+	e.saveButton = widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), func() {
+		e.save()
+	})
+	e.saveButton.Importance = widget.HighImportance
+	e.saveButton.Disable()
+
+	e.statusLabel = widget.NewLabel("")
+	e.statusLabel.Wrapping = fyne.TextWrapWord
+
+	tierHelp := widget.NewLabel("Trackers in the first tier are tried first, and later tiers are backups. " +
+		"The arrows move a tracker to the tier above or below.")
+	tierHelp.Wrapping = fyne.TextWrapWord
+	tierHelp.Importance = widget.LowImportance
+
+	e.Content = container.NewBorder(
+		tierHelp,
+		container.NewVBox(
+			e.pasteInput,
+			container.NewHBox(addButton, layout.NewSpacer(), revertButton, e.saveButton),
+			e.statusLabel,
+		),
+		nil, nil,
+		e.list,
+	)
+	return e
+}
+
+// SetTorrent shows the trackers of item, discarding unsaved edits
+func (e *trackerEditor) SetTorrent(item *TorrentItem) {
+	e.item = item
+	e.tiers = nil
+	if item != nil {
+		e.tiers = dropEmptyTiers(cloneTiers(item.Trackers))
+	}
+	e.updateEntries()
+	e.changed = false
+	e.statuses = nil
+	e.saveButton.Disable()
+	e.statusLabel.SetText("")
+	e.list.UnselectAll()
+	e.list.Refresh()
+}
+
+// UpdateStatus shows the latest announce states, keyed by tracker URL
+func (e *trackerEditor) UpdateStatus(statuses map[string]TrackerStatus) {
+	e.statuses = statuses
+	e.list.Refresh()
+}
+
+// updateEntries lists a row for each tracker of the edited tiers
+func (e *trackerEditor) updateEntries() {
+	e.entries = e.entries[:0]
+	for tier, urls := range e.tiers {
+		for index := range urls {
+			e.entries = append(e.entries, trackerEntry{tier: tier, index: index})
+		}
+	}
+}
+
+// markChanged enables saving after an edit
+func (e *trackerEditor) markChanged() {
+	e.updateEntries()
+	e.changed = true
+	e.saveButton.Enable()
+	e.statusLabel.SetText("Unsaved changes")
+	e.list.Refresh()
+}
+
+// save replaces the torrent's announce list with the edited one, tiers and all. anacrolix
+// can start new trackers right away, but it can't stop individual ones, so removals and
+// tier changes take effect when the torrent is restored on the next launch.
+func (e *trackerEditor) save() {
+	if e.item == nil || !e.changed {
+		return
+	}
+
+	old := dropEmptyTiers(e.item.Trackers)
+	previous := make(map[string]bool)
+	for _, u := range flattenTrackers(old) {
+		previous[u] = true
+	}
+
+	// Split the edit into new trackers and the tiers of the ones kept
+	added := make([]string, 0)
+	isAdded := make(map[string]bool)
+	for _, u := range flattenTrackers(e.tiers) {
+		if !previous[u] {
+			added = append(added, u)
+			isAdded[u] = true
+		}
+	}
+	removedOrMoved := !slices.EqualFunc(withoutTrackers(e.tiers, isAdded), old, slices.Equal)
+
+	e.item.Trackers = cloneTiers(e.tiers)
+	if len(added) > 0 && e.item.Handle != nil {
+		e.item.Handle.AddTrackers(withoutTrackers(e.tiers, previous))
+	}
+
+	e.changed = false
+	e.saveButton.Disable()
+	if removedOrMoved {
+		e.statusLabel.SetText("Saved. Removed trackers and tier changes take effect the next time Reed starts.")
+	} else {
+		e.statusLabel.SetText("Saved.")
+	}
+
+	if e.OnSaved != nil {
+		e.OnSaved()
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Webhook delivery limits: each attempt may take webhookTimeout, and a failed delivery is
+// retried up to webhookAttempts times in all, waiting longer after each failure
+const (
+	webhookTimeout  = 10 * time.Second
+	webhookAttempts = 3
+	webhookBackoff  = 5 * time.Second
+)
+
+// WebhookPayload is the JSON body posted to the webhook when a torrent completes
+type WebhookPayload struct {
+	Event       string    `json:"event"`
+	Name        string    `json:"name"`
+	InfoHash    string    `json:"info_hash"`
+	Size        int64     `json:"size"`
+	SavePath    string    `json:"save_path"`
+	Category    string    `json:"category,omitempty"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// newCompletionPayload describes a completed torrent for the webhook
+func newCompletionPayload(infoHash string, item *TorrentItem) WebhookPayload {
+	return WebhookPayload{
+		Event:       "completed",
+		Name:        item.Name,
+		InfoHash:    infoHash,
+		Size:        item.Size,
+		SavePath:    item.SavePath,
+		Category:    item.Category,
+		CompletedAt: item.CompletedAt,
+	}
+}
+
+// validateWebhookURL accepts an absolute http or https URL
+func validateWebhookURL(text string) error {
+	u, err := url.Parse(strings.TrimSpace(text))
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("enter an http:// or https:// URL")
+	}
+	return nil
+}
+
+// postWebhook delivers payload to link with transport, retrying attempts that fail for a
+// reason that may pass, such as a network error or a busy server. Any 2xx response counts
+// as delivered, and other errors such as 404 are returned at once. offline is checked
+// before each retry, which is skipped if it returns an error.
+func postWebhook(transport http.RoundTripper, link string, payload WebhookPayload, offline func() error) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Transport: transport, Timeout: webhookTimeout}
+	for attempt := 1; ; attempt++ {
+		var transient bool
+		transient, err = func() (bool, error) {
+			resp, err := client.Post(link, "application/json", bytes.NewReader(body))
+			if err != nil {
+				return true, err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				return retryableStatus(resp.StatusCode), fmt.Errorf("server returned %s", resp.Status)
+			}
+			return false, nil
+		}()
+		if err == nil || !transient {
+			return err
+		}
+		if attempt == webhookAttempts {
+			return fmt.Errorf("after %d attempts: %v", webhookAttempts, err)
+		}
+		time.Sleep(time.Duration(attempt) * webhookBackoff)
+		if offlineErr := offline(); offlineErr != nil {
+			return offlineErr
+		}
+	}
+}